@@ -36,17 +36,43 @@ func (p *Program) TokenLiteral() string {
 
 func (p *Program) String() string {
 	var out bytes.Buffer
+	writeStatements(&out, p.Statements)
+	return out.String()
+}
 
-	for _, s := range p.Statements {
+// writeStatements writes stmts so that they parse back to the same list: a
+// statement that the next one could continue, as `a` in `a; (b)`, is ended
+// with a semicolon.
+func writeStatements(out *bytes.Buffer, stmts []Statement) {
+	for i, s := range stmts {
 		out.WriteString(s.String())
+		if i < len(stmts)-1 {
+			out.WriteString(terminator(s))
+		}
 	}
+}
 
-	return out.String()
+// terminator returns the semicolon that ends s, unless s already ends with
+// one or cannot be followed by one (switch).
+func terminator(s Statement) string {
+	if _, ok := s.(*SwitchStatement); ok || strings.HasSuffix(s.String(), ";") {
+		return ""
+	}
+	return ";"
+}
+
+// blockString writes a block with its braces.
+func blockString(b *BlockStatement) string {
+	if len(b.Statements) == 0 {
+		return "{}"
+	}
+	return "{ " + b.String() + " }"
 }
 
 type LetStatement struct {
 	Token token.Token
 	Name  *Identifier
+	Type  TypeExpression // let <name>: <type> = ... の型注釈. なければ nil
 	Value Expression
 }
 
@@ -59,6 +85,9 @@ func (ls *LetStatement) String() string {
 
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -72,6 +101,12 @@ func (ls *LetStatement) String() string {
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	// resolver が設定する変数の位置. Resolved が false の識別子は
+	// 実行時に名前で検索する (グローバル変数や組み込み関数)
+	Resolved bool
+	Depth    int // 参照元の環境から何段外側の環境か
+	Slot     int // 環境内の添字
 }
 
 func (i *Identifier) expressionNode() {}
@@ -132,6 +167,19 @@ func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -206,14 +254,14 @@ func (ie *IfExpression) TokenLiteral() string {
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("if")
+	out.WriteString("if (")
 	out.WriteString(ie.Condition.String())
-	out.WriteString(" ")
-	out.WriteString(ie.Consequence.String())
+	out.WriteString(") ")
+	out.WriteString(blockString(ie.Consequence))
 
 	if ie.Alternative != nil {
-		out.WriteString("else ")
-		out.WriteString(ie.Alternative.String())
+		out.WriteString(" else ")
+		out.WriteString(blockString(ie.Alternative))
 	}
 
 	return out.String()
@@ -230,6 +278,7 @@ type ForExpression struct {
 	FinishCondition Expression
 	LoopStatement   Statement
 	Consequence     *BlockStatement
+	Slots           int // resolver が割り当てたループ環境の大きさ
 }
 
 func (fe *ForExpression) expressionNode() {}
@@ -238,21 +287,23 @@ func (fe *ForExpression) TokenLiteral() string {
 }
 func (fe *ForExpression) String() string {
 	var out bytes.Buffer
-	out.WriteString("for")
+	out.WriteString("for (")
 	out.WriteString(fe.InitStatement.String())
-	out.WriteString("; ")
+	// let 文は ; で終わっている
+	out.WriteString(terminator(fe.InitStatement) + " ")
 	out.WriteString(fe.FinishCondition.String())
 	out.WriteString("; ")
 	out.WriteString(fe.LoopStatement.String())
-	out.WriteString(" ")
-	out.WriteString(fe.Consequence.String())
+	out.WriteString(") ")
+	out.WriteString(blockString(fe.Consequence))
 
 	return out.String()
 }
 
 type BlockStatement struct {
-	Token      token.Token
+	Token      token.Token // '{' token
 	Statements []Statement
+	End        token.Token // '}' token
 }
 
 func (bs *BlockStatement) statementNode() {}
@@ -261,18 +312,23 @@ func (bs *BlockStatement) TokenLiteral() string {
 }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
-
-	for _, s := range bs.Statements {
-		out.WriteString(s.String())
-	}
-
+	writeStatements(&out, bs.Statements)
 	return out.String()
 }
 
+// fn(<param>, <param> = <default>, ...<rest>) { <body> }
+// fn <name>(<param>: <type>): <type> { <body> }
 type FunctionLiteral struct {
-	Token      token.Token // 'fn' token
-	Parameters []*Identifier
-	Body       *BlockStatement
+	Token          token.Token // 'fn' token
+	Name           *Identifier // 宣言された関数名. 無名関数は nil
+	Parameters     []*Identifier
+	ParameterTypes []TypeExpression // Parameters と同じ長さ. 型注釈のない引数は nil
+	Defaults       []Expression     // Parameters と同じ長さ. デフォルト値のない引数は nil
+	Rest           *Identifier
+	RestType       TypeExpression
+	ReturnType     TypeExpression
+	Body           *BlockStatement
+	Slots          int // resolver が割り当てた関数環境の大きさ
 }
 
 func (fl *FunctionLiteral) expressionNode() {
@@ -285,20 +341,62 @@ func (fl *FunctionLiteral) TokenLiteral() string {
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
-	}
-
 	out.WriteString(fl.TokenLiteral())
+	if fl.Name != nil {
+		out.WriteString(" " + fl.Name.String())
+	}
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") ")
-	out.WriteString(fl.Body.String())
+	out.WriteString(strings.Join(fl.parameterStrings(), ", "))
+	out.WriteString(")")
+	if fl.ReturnType != nil {
+		out.WriteString(": " + fl.ReturnType.String())
+	}
+	out.WriteString(" ")
+	out.WriteString(blockString(fl.Body))
 
 	return out.String()
 }
 
+// parameterStrings is ParameterStrings with the type annotations.
+func (fl *FunctionLiteral) parameterStrings() []string {
+	out := []string{}
+	for i, p := range fl.Parameters {
+		param := p.String()
+		if i < len(fl.ParameterTypes) && fl.ParameterTypes[i] != nil {
+			param += ": " + fl.ParameterTypes[i].String()
+		}
+		if i < len(fl.Defaults) && fl.Defaults[i] != nil {
+			param += " = " + fl.Defaults[i].String()
+		}
+		out = append(out, param)
+	}
+	if fl.Rest != nil {
+		rest := "..." + fl.Rest.String()
+		if fl.RestType != nil {
+			rest += ": " + fl.RestType.String()
+		}
+		out = append(out, rest)
+	}
+	return out
+}
+
+// ParameterStrings renders a parameter list with its default values and
+// rest parameter.
+func ParameterStrings(params []*Identifier, defaults []Expression, rest *Identifier) []string {
+	out := []string{}
+	for i, p := range params {
+		if i < len(defaults) && defaults[i] != nil {
+			out = append(out, p.String()+" = "+defaults[i].String())
+		} else {
+			out = append(out, p.String())
+		}
+	}
+	if rest != nil {
+		out = append(out, "..."+rest.String())
+	}
+	return out
+}
+
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
@@ -352,12 +450,19 @@ func (sl *StringLiteral) TokenLiteral() string {
 	return sl.Token.Literal
 }
 func (sl *StringLiteral) String() string {
-	return sl.Token.Literal
+	return quote(sl.Value)
+}
+
+// quote writes s as a string literal with the escapes the lexer reads.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
 }
 
 type ArrayLiteral struct {
-	Token    token.Token
+	Token    token.Token // '[' token
 	Elements []Expression
+	End      token.Token // ']' token
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
@@ -397,8 +502,29 @@ func (ie *IndexExpression) String() string {
 }
 
 type HashLiteral struct {
-	Token token.Token
+	Token token.Token // '{' token
 	Pairs map[Expression]Expression
+	Keys  []Expression // Pairs のキーを書かれた順に並べたもの
+	End   token.Token  // '}' token
+}
+
+// OrderedKeys returns the keys of Pairs in source order. Keys missing from
+// the Keys slice, as in literals built by hand, follow in map order.
+func (hl *HashLiteral) OrderedKeys() []Expression {
+	keys := []Expression{}
+	seen := map[Expression]bool{}
+	for _, key := range hl.Keys {
+		if _, ok := hl.Pairs[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	for key := range hl.Pairs {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -406,8 +532,8 @@ func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.OrderedKeys() {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -419,6 +545,7 @@ type SwitchStatement struct {
 	Token      token.Token
 	Expression Expression
 	Case       []*CaseStatement
+	End        token.Token // '}' token
 }
 
 func (ss *SwitchStatement) statementNode() {}
@@ -428,7 +555,11 @@ func (ss *SwitchStatement) TokenLiteral() string {
 func (ss *SwitchStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("switch {\n")
+	out.WriteString("switch ")
+	if ss.Expression != nil {
+		out.WriteString(ss.Expression.String() + " ")
+	}
+	out.WriteString("{\n")
 	for _, c := range ss.Case {
 		out.WriteString(c.String() + "\n")
 	}
@@ -440,6 +571,7 @@ type CaseStatement struct {
 	Token      token.Token
 	Condition  Expression
 	Statements []Statement
+	Break      bool // break で終わっているか
 }
 
 func (cs *CaseStatement) statementNode() {}
@@ -449,11 +581,111 @@ func (cs *CaseStatement) TokenLiteral() string {
 func (cs *CaseStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("case")
-	out.WriteString(cs.Condition.String() + "\n")
-	for _, s := range cs.Statements {
+	out.WriteString("case ")
+	out.WriteString(cs.Condition.String() + ":\n")
+	for i, s := range cs.Statements {
 		out.WriteString("\t" + s.String())
+		if i < len(cs.Statements)-1 {
+			out.WriteString(terminator(s))
+		}
+		out.WriteString("\n")
+	}
+	if cs.Break {
+		out.WriteString("\tbreak;")
+	}
+
+	return out.String()
+}
+
+// import "<path>" as <alias>;
+// import { <name>, <name> as <alias> } from "<path>";
+type ImportStatement struct {
+	Token token.Token // 'import' token
+	Path  *StringLiteral
+	Alias *Identifier
+	Names []*ImportName
+}
+
+type ImportName struct {
+	Name  *Identifier
+	Alias *Identifier
+}
+
+// Binding returns the identifier the imported value is bound to.
+func (in *ImportName) Binding() *Identifier {
+	if in.Alias != nil {
+		return in.Alias
+	}
+	return in.Name
+}
+
+func (is *ImportStatement) statementNode() {}
+func (is *ImportStatement) TokenLiteral() string {
+	return is.Token.Literal
+}
+func (is *ImportStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.TokenLiteral() + " ")
+	if is.Names != nil {
+		names := []string{}
+		for _, n := range is.Names {
+			if n.Alias != nil {
+				names = append(names, n.Name.String()+" as "+n.Alias.String())
+			} else {
+				names = append(names, n.Name.String())
+			}
+		}
+		out.WriteString("{ " + strings.Join(names, ", ") + " } from ")
+	}
+	out.WriteString(is.Path.String())
+	if is.Alias != nil {
+		out.WriteString(" as " + is.Alias.String())
 	}
+	out.WriteString(";")
 
 	return out.String()
 }
+
+// export let <name> = <expression>;
+type ExportStatement struct {
+	Token     token.Token // 'export' token
+	Statement *LetStatement
+}
+
+func (es *ExportStatement) statementNode() {}
+func (es *ExportStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Statement.String()
+}
+
+// <expression>.<name>
+type DotExpression struct {
+	Token token.Token // '.' token
+	Left  Expression
+	Name  *Identifier
+}
+
+func (de *DotExpression) expressionNode() {}
+func (de *DotExpression) TokenLiteral() string {
+	return de.Token.Literal
+}
+func (de *DotExpression) String() string {
+	return de.Left.String() + "." + de.Name.String()
+}
+
+// ...<expression>
+type SpreadExpression struct {
+	Token token.Token // '...' token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode() {}
+func (se *SpreadExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
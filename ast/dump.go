@@ -0,0 +1,197 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// Fprint writes the AST rooted at node to w as an indented tree: one
+// line per node with its type and position, followed by its fields.
+// Fields holding zero values or lists of nil are omitted, except Value so
+// that literals such as 0 and "" are shown.
+//
+//	Program
+//	  Statements[0]: LetStatement 1:1
+//	    Name: Identifier 1:5
+//	      Value: "x"
+//	    Value: IntegerLiteral 1:9
+//	      Value: 5
+func Fprint(w io.Writer, node Node) error {
+	var out bytes.Buffer
+	d := dump(reflect.ValueOf(node))
+	if n, ok := d.(*dumpNode); ok {
+		out.WriteString(n.header() + "\n")
+		n.fprint(&out, 1)
+	} else {
+		fmt.Fprintln(&out, d)
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// MarshalJSON encodes the AST rooted at node as JSON. Every node is an
+// object with its type in "node", its position in "line" and "column" and
+// its fields, named in lower camel case. The same fields are omitted as
+// by Fprint.
+func MarshalJSON(node Node) ([]byte, error) {
+	return json.Marshal(dump(reflect.ValueOf(node)))
+}
+
+type dumpNode struct {
+	typ    string
+	pos    token.Token
+	fields []dumpField
+}
+
+type dumpField struct {
+	name  string
+	value interface{} // *dumpNode, []interface{}, position, スカラー値
+}
+
+type position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+func (n *dumpNode) header() string {
+	if n.pos.Line == 0 {
+		return n.typ
+	}
+	return fmt.Sprintf("%s %d:%d", n.typ, n.pos.Line, n.pos.Column)
+}
+
+func (n *dumpNode) fprint(out *bytes.Buffer, depth int) {
+	for _, f := range n.fields {
+		fprintField(out, depth, f.name, f.value)
+	}
+}
+
+func fprintField(out *bytes.Buffer, depth int, name string, value interface{}) {
+	indent := strings.Repeat("  ", depth)
+	switch v := value.(type) {
+	case *dumpNode:
+		out.WriteString(indent + name + ": " + v.header() + "\n")
+		v.fprint(out, depth+1)
+	case []interface{}:
+		for i, el := range v {
+			fprintField(out, depth, fmt.Sprintf("%s[%d]", name, i), el)
+		}
+	case nil:
+		out.WriteString(indent + name + ": nil\n")
+	case string:
+		out.WriteString(indent + name + ": " + fmt.Sprintf("%q", v) + "\n")
+	default:
+		out.WriteString(indent + name + ": " + fmt.Sprint(v) + "\n")
+	}
+}
+
+func (n *dumpNode) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString(`{"node":` + fmt.Sprintf("%q", n.typ))
+	if n.pos.Line != 0 {
+		fmt.Fprintf(&out, `,"line":%d,"column":%d`, n.pos.Line, n.pos.Column)
+	}
+	for _, f := range n.fields {
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(",")
+		out.WriteString(fmt.Sprintf("%q", lowerFirst(f.name)) + ":")
+		out.Write(value)
+	}
+	out.WriteString("}")
+	return out.Bytes(), nil
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// dump converts a node, a slice of nodes or a field value to the tree
+// printed by Fprint and MarshalJSON.
+func dump(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return dump(v.Elem())
+	case reflect.Slice:
+		list := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, dump(v.Index(i)))
+		}
+		return list
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			tok := v.Interface().(token.Token)
+			return position{Line: tok.Line, Column: tok.Column}
+		}
+		return dumpStruct(v)
+	}
+	return v.Interface()
+}
+
+func dumpStruct(v reflect.Value) *dumpNode {
+	n := &dumpNode{typ: v.Type().Name()}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		switch {
+		case field.PkgPath != "":
+			// 非公開のフィールド
+		case field.Name == "Token" && field.Type == tokenType:
+			n.pos = value.Interface().(token.Token)
+		case field.Name == "Keys" && v.Type() == reflect.TypeOf(HashLiteral{}):
+			// Pairs と一緒に出力する
+		case field.Name == "Pairs":
+			n.fields = append(n.fields, dumpField{field.Name, dumpPairs(v.Addr().Interface().(*HashLiteral))})
+		case value.IsZero() && field.Name != "Value":
+		case value.Kind() == reflect.Slice && allNil(value):
+			// 型注釈やデフォルト値のない引数の並びなど
+		default:
+			n.fields = append(n.fields, dumpField{field.Name, dump(value)})
+		}
+	}
+	return n
+}
+
+// allNil reports whether every element of the slice v is nil, which is
+// true of empty slices.
+func allNil(v reflect.Value) bool {
+	for i := 0; i < v.Len(); i++ {
+		el := v.Index(i)
+		if k := el.Kind(); k != reflect.Interface && k != reflect.Ptr || !el.IsNil() {
+			return false
+		}
+	}
+	return true
+}
+
+// dumpPairs lists the pairs of a hash literal in source order.
+func dumpPairs(hash *HashLiteral) []interface{} {
+	pairs := []interface{}{}
+	for _, key := range hash.OrderedKeys() {
+		pairs = append(pairs, &dumpNode{typ: "Pair", fields: []dumpField{
+			{"Key", dump(reflect.ValueOf(key))},
+			{"Value", dump(reflect.ValueOf(hash.Pairs[key]))},
+		}})
+	}
+	return pairs
+}
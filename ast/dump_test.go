@@ -0,0 +1,127 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+func dumpTestProgram() *Program {
+	key := &StringLiteral{Token: token.Token{Type: token.STRING, Literal: "a", Line: 2, Column: 10}, Value: "a"}
+	return &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let", Line: 1, Column: 1},
+				Name:  &Identifier{Token: token.Token{Type: token.IDENT, Literal: "x", Line: 1, Column: 5}, Value: "x"},
+				Type:  &NamedType{Token: token.Token{Type: token.IDENT, Literal: "int", Line: 1, Column: 8}, Name: "int"},
+				Value: &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "0", Line: 1, Column: 14}, Value: 0},
+			},
+			&ExpressionStatement{
+				Token: token.Token{Type: token.LBRACE, Literal: "{", Line: 2, Column: 9},
+				Expression: &HashLiteral{
+					Token: token.Token{Type: token.LBRACE, Literal: "{", Line: 2, Column: 9},
+					Pairs: map[Expression]Expression{
+						key: &FunctionLiteral{
+							Token:          token.Token{Type: token.FUNCTION, Literal: "fn", Line: 2, Column: 15},
+							Parameters:     []*Identifier{{Token: token.Token{Type: token.IDENT, Literal: "a", Line: 2, Column: 18}, Value: "a"}},
+							ParameterTypes: []TypeExpression{nil},
+							Defaults:       []Expression{nil},
+							Body: &BlockStatement{
+								Token: token.Token{Type: token.LBRACE, Literal: "{", Line: 2, Column: 21},
+								End:   token.Token{Type: token.RBRACE, Literal: "}", Line: 2, Column: 22},
+							},
+						},
+					},
+					Keys: []Expression{key},
+				},
+			},
+		},
+	}
+}
+
+func TestFprint(t *testing.T) {
+	expected := `Program
+  Statements[0]: LetStatement 1:1
+    Name: Identifier 1:5
+      Value: "x"
+    Type: NamedType 1:8
+      Name: "int"
+    Value: IntegerLiteral 1:14
+      Value: 0
+  Statements[1]: ExpressionStatement 2:9
+    Expression: HashLiteral 2:9
+      Pairs[0]: Pair
+        Key: StringLiteral 2:10
+          Value: "a"
+        Value: FunctionLiteral 2:15
+          Parameters[0]: Identifier 2:18
+            Value: "a"
+          Body: BlockStatement 2:21
+            End: 2:22
+`
+
+	var out bytes.Buffer
+	if err := Fprint(&out, dumpTestProgram()); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("wrong output.\ngot:\n%s\nwant:\n%s", out.String(), expected)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	data, err := MarshalJSON(dumpTestProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var program struct {
+		Node       string `json:"node"`
+		Statements []struct {
+			Node   string `json:"node"`
+			Line   int    `json:"line"`
+			Column int    `json:"column"`
+			Type   *struct {
+				Node string `json:"node"`
+				Name string `json:"name"`
+			} `json:"type"`
+			Value *struct {
+				Node  string      `json:"node"`
+				Value interface{} `json:"value"`
+			} `json:"value"`
+			Expression *struct {
+				Pairs []struct {
+					Key   map[string]interface{} `json:"key"`
+					Value map[string]interface{} `json:"value"`
+				} `json:"pairs"`
+			} `json:"expression"`
+		} `json:"statements"`
+	}
+	if err := json.Unmarshal(data, &program); err != nil {
+		t.Fatalf("invalid JSON: %s\n%s", err, data)
+	}
+
+	if program.Node != "Program" || len(program.Statements) != 2 {
+		t.Fatalf("wrong program: %s", data)
+	}
+	let := program.Statements[0]
+	if let.Node != "LetStatement" || let.Line != 1 || let.Column != 1 {
+		t.Errorf("wrong let statement: %+v", let)
+	}
+	if let.Type == nil || let.Type.Node != "NamedType" || let.Type.Name != "int" {
+		t.Errorf("wrong type annotation: %s", data)
+	}
+	if let.Value == nil || let.Value.Node != "IntegerLiteral" || let.Value.Value != 0.0 {
+		t.Errorf("wrong value: %s", data)
+	}
+	hash := program.Statements[1].Expression
+	if hash == nil || len(hash.Pairs) != 1 || hash.Pairs[0].Key["value"] != "a" ||
+		hash.Pairs[0].Value["node"] != "FunctionLiteral" {
+		t.Errorf("wrong hash literal: %s", data)
+	}
+	if _, ok := hash.Pairs[0].Value["parameterTypes"]; ok {
+		t.Errorf("lists of nil should be omitted: %s", data)
+	}
+}
@@ -0,0 +1,203 @@
+package ast
+
+import "reflect"
+
+// ModifierFunc returns the node that replaces node in the tree.
+type ModifierFunc func(node Node) Node
+
+// Modify rewrites an AST bottom-up: the children of node are modified
+// first, then node itself is passed to modifier and the result replaces
+// it in its parent. A replacement must fit the field it is stored in (an
+// Expression in place of an expression, a *BlockStatement in place of a
+// block, and so on); a result that does not fit is ignored and the
+// original node is kept.
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch n := node.(type) {
+	case *Program:
+		n.Statements = modifyStatements(n.Statements, modifier)
+	case *LetStatement:
+		n.Name = modifyIdent(n.Name, modifier)
+		n.Type = modifyType(n.Type, modifier)
+		n.Value = modifyExpr(n.Value, modifier)
+	case *ReturnStatement:
+		n.ReturnValue = modifyExpr(n.ReturnValue, modifier)
+	case *ExpressionStatement:
+		n.Expression = modifyExpr(n.Expression, modifier)
+	case *BlockStatement:
+		n.Statements = modifyStatements(n.Statements, modifier)
+	case *DoublePlusStatement:
+		n.Name = modifyIdent(n.Name, modifier)
+	case *SwitchStatement:
+		n.Expression = modifyExpr(n.Expression, modifier)
+		for i, c := range n.Case {
+			if c == nil {
+				continue
+			}
+			if c, ok := Modify(c, modifier).(*CaseStatement); ok {
+				n.Case[i] = c
+			}
+		}
+	case *CaseStatement:
+		n.Condition = modifyExpr(n.Condition, modifier)
+		n.Statements = modifyStatements(n.Statements, modifier)
+	case *ImportStatement:
+		if n.Path != nil {
+			if path, ok := Modify(n.Path, modifier).(*StringLiteral); ok {
+				n.Path = path
+			}
+		}
+		n.Alias = modifyIdent(n.Alias, modifier)
+		for _, name := range n.Names {
+			name.Name = modifyIdent(name.Name, modifier)
+			name.Alias = modifyIdent(name.Alias, modifier)
+		}
+	case *ExportStatement:
+		if n.Statement != nil {
+			if let, ok := Modify(n.Statement, modifier).(*LetStatement); ok {
+				n.Statement = let
+			}
+		}
+
+	case *PrefixExpression:
+		n.Right = modifyExpr(n.Right, modifier)
+	case *InfixExpression:
+		n.Left = modifyExpr(n.Left, modifier)
+		n.Right = modifyExpr(n.Right, modifier)
+	case *IfExpression:
+		n.Condition = modifyExpr(n.Condition, modifier)
+		n.Consequence = modifyBlock(n.Consequence, modifier)
+		n.Alternative = modifyBlock(n.Alternative, modifier)
+	case *ForExpression:
+		n.InitStatement = modifyStmt(n.InitStatement, modifier)
+		n.FinishCondition = modifyExpr(n.FinishCondition, modifier)
+		n.LoopStatement = modifyStmt(n.LoopStatement, modifier)
+		n.Consequence = modifyBlock(n.Consequence, modifier)
+	case *FunctionLiteral:
+		n.Name = modifyIdent(n.Name, modifier)
+		for i, p := range n.Parameters {
+			n.Parameters[i] = modifyIdent(p, modifier)
+			if i < len(n.ParameterTypes) {
+				n.ParameterTypes[i] = modifyType(n.ParameterTypes[i], modifier)
+			}
+			if i < len(n.Defaults) {
+				n.Defaults[i] = modifyExpr(n.Defaults[i], modifier)
+			}
+		}
+		n.Rest = modifyIdent(n.Rest, modifier)
+		n.RestType = modifyType(n.RestType, modifier)
+		n.ReturnType = modifyType(n.ReturnType, modifier)
+		n.Body = modifyBlock(n.Body, modifier)
+	case *CallExpression:
+		n.Function = modifyExpr(n.Function, modifier)
+		n.Arguments = modifyExprs(n.Arguments, modifier)
+	case *ArrayLiteral:
+		n.Elements = modifyExprs(n.Elements, modifier)
+	case *IndexExpression:
+		n.Left = modifyExpr(n.Left, modifier)
+		n.Index = modifyExpr(n.Index, modifier)
+	case *HashLiteral:
+		// キーが置き換わるので map と順序を作り直す
+		pairs := make(map[Expression]Expression, len(n.Pairs))
+		keys := []Expression{}
+		for _, key := range n.OrderedKeys() {
+			value := n.Pairs[key]
+			key = modifyExpr(key, modifier)
+			pairs[key] = modifyExpr(value, modifier)
+			keys = append(keys, key)
+		}
+		n.Pairs = pairs
+		n.Keys = keys
+	case *DotExpression:
+		n.Left = modifyExpr(n.Left, modifier)
+		n.Name = modifyIdent(n.Name, modifier)
+	case *SpreadExpression:
+		n.Value = modifyExpr(n.Value, modifier)
+
+	case *ArrayType:
+		n.Element = modifyType(n.Element, modifier)
+	case *HashType:
+		n.Key = modifyType(n.Key, modifier)
+		n.Value = modifyType(n.Value, modifier)
+	case *FunctionType:
+		for i, p := range n.Parameters {
+			n.Parameters[i] = modifyType(p, modifier)
+		}
+		n.Rest = modifyType(n.Rest, modifier)
+		n.Return = modifyType(n.Return, modifier)
+	}
+
+	return modifier(node)
+}
+
+func modifyIdent(ident *Identifier, modifier ModifierFunc) *Identifier {
+	if ident == nil {
+		return ident
+	}
+	if result, ok := Modify(ident, modifier).(*Identifier); ok {
+		return result
+	}
+	return ident
+}
+
+func modifyBlock(block *BlockStatement, modifier ModifierFunc) *BlockStatement {
+	if block == nil {
+		return block
+	}
+	if result, ok := Modify(block, modifier).(*BlockStatement); ok {
+		return result
+	}
+	return block
+}
+
+func modifyExpr(exp Expression, modifier ModifierFunc) Expression {
+	if isNil(exp) {
+		return exp
+	}
+	if result, ok := Modify(exp, modifier).(Expression); ok && !isNil(result) {
+		return result
+	}
+	return exp
+}
+
+func modifyStmt(stmt Statement, modifier ModifierFunc) Statement {
+	if isNil(stmt) {
+		return stmt
+	}
+	if result, ok := Modify(stmt, modifier).(Statement); ok && !isNil(result) {
+		return result
+	}
+	return stmt
+}
+
+func modifyType(typ TypeExpression, modifier ModifierFunc) TypeExpression {
+	if isNil(typ) {
+		return typ
+	}
+	if result, ok := Modify(typ, modifier).(TypeExpression); ok && !isNil(result) {
+		return result
+	}
+	return typ
+}
+
+func modifyExprs(exps []Expression, modifier ModifierFunc) []Expression {
+	for i, exp := range exps {
+		exps[i] = modifyExpr(exp, modifier)
+	}
+	return exps
+}
+
+func modifyStatements(stmts []Statement, modifier ModifierFunc) []Statement {
+	for i, stmt := range stmts {
+		stmts[i] = modifyStmt(stmt, modifier)
+	}
+	return stmts
+}
+
+// isNil reports whether node is nil or an interface holding a nil pointer.
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
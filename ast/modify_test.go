@@ -0,0 +1,158 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModify(t *testing.T) {
+	one := func() Expression { return integer(1) }
+	two := func() Expression { return integer(2) }
+
+	turnOneIntoTwo := func(node Node) Node {
+		integer, ok := node.(*IntegerLiteral)
+		if !ok {
+			return node
+		}
+		if integer.Value != 1 {
+			return node
+		}
+		integer.Value = 2
+		return integer
+	}
+
+	block := func(exp Expression) *BlockStatement {
+		return &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: exp}}}
+	}
+
+	tests := []struct {
+		input    Node
+		expected Node
+	}{
+		{one(), two()},
+		{
+			&Program{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			&Program{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+		},
+		{
+			&InfixExpression{Left: one(), Operator: "+", Right: two()},
+			&InfixExpression{Left: two(), Operator: "+", Right: two()},
+		},
+		{
+			&InfixExpression{Left: two(), Operator: "+", Right: one()},
+			&InfixExpression{Left: two(), Operator: "+", Right: two()},
+		},
+		{
+			&PrefixExpression{Operator: "-", Right: one()},
+			&PrefixExpression{Operator: "-", Right: two()},
+		},
+		{
+			&IndexExpression{Left: one(), Index: one()},
+			&IndexExpression{Left: two(), Index: two()},
+		},
+		{
+			&IfExpression{Condition: one(), Consequence: block(one()), Alternative: block(one())},
+			&IfExpression{Condition: two(), Consequence: block(two()), Alternative: block(two())},
+		},
+		{
+			&ForExpression{
+				InitStatement:   &LetStatement{Name: ident("i"), Value: one()},
+				FinishCondition: one(),
+				LoopStatement:   &ExpressionStatement{Expression: one()},
+				Consequence:     block(one()),
+			},
+			&ForExpression{
+				InitStatement:   &LetStatement{Name: ident("i"), Value: two()},
+				FinishCondition: two(),
+				LoopStatement:   &ExpressionStatement{Expression: two()},
+				Consequence:     block(two()),
+			},
+		},
+		{
+			&ReturnStatement{ReturnValue: one()},
+			&ReturnStatement{ReturnValue: two()},
+		},
+		{
+			&LetStatement{Name: ident("x"), Value: one()},
+			&LetStatement{Name: ident("x"), Value: two()},
+		},
+		{
+			&FunctionLiteral{Parameters: []*Identifier{ident("a")}, Defaults: []Expression{one()}, Body: block(one())},
+			&FunctionLiteral{Parameters: []*Identifier{ident("a")}, Defaults: []Expression{two()}, Body: block(two())},
+		},
+		{
+			&CallExpression{Function: ident("f"), Arguments: []Expression{one(), &SpreadExpression{Value: one()}}},
+			&CallExpression{Function: ident("f"), Arguments: []Expression{two(), &SpreadExpression{Value: two()}}},
+		},
+		{
+			&ArrayLiteral{Elements: []Expression{one(), one()}},
+			&ArrayLiteral{Elements: []Expression{two(), two()}},
+		},
+		{
+			&SwitchStatement{Expression: one(), Case: []*CaseStatement{
+				{Condition: one(), Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			}},
+			&SwitchStatement{Expression: two(), Case: []*CaseStatement{
+				{Condition: two(), Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+			}},
+		},
+		{
+			&ExportStatement{Statement: &LetStatement{Name: ident("x"), Value: one()}},
+			&ExportStatement{Statement: &LetStatement{Name: ident("x"), Value: two()}},
+		},
+		{
+			&DotExpression{Left: &ArrayLiteral{Elements: []Expression{one()}}, Name: ident("x")},
+			&DotExpression{Left: &ArrayLiteral{Elements: []Expression{two()}}, Name: ident("x")},
+		},
+	}
+
+	for i, tt := range tests {
+		modified := Modify(tt.input, turnOneIntoTwo)
+		if !reflect.DeepEqual(modified, tt.expected) {
+			t.Errorf("tests[%d]: not equal. got=%#v, want=%#v", i, modified, tt.expected)
+		}
+	}
+
+	hashLiteral := &HashLiteral{Pairs: map[Expression]Expression{}}
+	k1, k2 := one(), one()
+	hashLiteral.Pairs[k1] = one()
+	hashLiteral.Pairs[k2] = one()
+	hashLiteral.Keys = []Expression{k1, k2}
+
+	Modify(hashLiteral, turnOneIntoTwo)
+
+	if len(hashLiteral.Keys) != 2 || len(hashLiteral.Pairs) != 2 {
+		t.Fatalf("wrong hash literal size. keys=%d pairs=%d", len(hashLiteral.Keys), len(hashLiteral.Pairs))
+	}
+	for _, key := range hashLiteral.Keys {
+		if key.(*IntegerLiteral).Value != 2 {
+			t.Errorf("key is not 2. got=%d", key.(*IntegerLiteral).Value)
+		}
+		if hashLiteral.Pairs[key].(*IntegerLiteral).Value != 2 {
+			t.Errorf("value is not 2. got=%d", hashLiteral.Pairs[key].(*IntegerLiteral).Value)
+		}
+	}
+}
+
+func TestModifyReplacesNodes(t *testing.T) {
+	// x を 10 に置き換える. Name の位置の識別子は式に置き換えられないので残る
+	program := &Program{Statements: []Statement{
+		&LetStatement{Name: ident("x"), Value: &InfixExpression{Left: ident("x"), Operator: "+", Right: integer(1)}},
+	}}
+
+	Modify(program, func(node Node) Node {
+		if id, ok := node.(*Identifier); ok && id.Value == "x" {
+			return integer(10)
+		}
+		return node
+	})
+
+	let := program.Statements[0].(*LetStatement)
+	if let.Name.Value != "x" {
+		t.Errorf("let name was replaced. got=%s", let.Name.Value)
+	}
+	infix := let.Value.(*InfixExpression)
+	if lit, ok := infix.Left.(*IntegerLiteral); !ok || lit.Value != 10 {
+		t.Errorf("identifier was not replaced. got=%#v", infix.Left)
+	}
+}
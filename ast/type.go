@@ -0,0 +1,89 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// TypeExpression is an optional type annotation on a let binding, a
+// parameter or a function result. The evaluator ignores annotations; they
+// are read by the checker package.
+type TypeExpression interface {
+	Node
+	typeNode()
+}
+
+// int, float, string, bool, null, any
+type NamedType struct {
+	Token token.Token
+	Name  string
+}
+
+func (nt *NamedType) typeNode() {}
+func (nt *NamedType) TokenLiteral() string {
+	return nt.Token.Literal
+}
+func (nt *NamedType) String() string {
+	return nt.Name
+}
+
+// [<element>]
+type ArrayType struct {
+	Token   token.Token // '[' token
+	Element TypeExpression
+}
+
+func (at *ArrayType) typeNode() {}
+func (at *ArrayType) TokenLiteral() string {
+	return at.Token.Literal
+}
+func (at *ArrayType) String() string {
+	return "[" + at.Element.String() + "]"
+}
+
+// {<key>: <value>}
+type HashType struct {
+	Token token.Token // '{' token
+	Key   TypeExpression
+	Value TypeExpression
+}
+
+func (ht *HashType) typeNode() {}
+func (ht *HashType) TokenLiteral() string {
+	return ht.Token.Literal
+}
+func (ht *HashType) String() string {
+	return "{" + ht.Key.String() + ": " + ht.Value.String() + "}"
+}
+
+// fn(<param>, ...<rest>): <return>
+type FunctionType struct {
+	Token      token.Token // 'fn' token
+	Parameters []TypeExpression
+	Rest       TypeExpression // 可変長引数の配列型. なければ nil
+	Return     TypeExpression // 省略されたら nil
+}
+
+func (ft *FunctionType) typeNode() {}
+func (ft *FunctionType) TokenLiteral() string {
+	return ft.Token.Literal
+}
+func (ft *FunctionType) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ft.Parameters {
+		params = append(params, p.String())
+	}
+	if ft.Rest != nil {
+		params = append(params, "..."+ft.Rest.String())
+	}
+	out.WriteString("fn(" + strings.Join(params, ", ") + ")")
+	if ft.Return != nil {
+		out.WriteString(": " + ft.Return.String())
+	}
+
+	return out.String()
+}
@@ -0,0 +1,187 @@
+package ast
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, followed by a call of w.Visit(nil).
+// Children are visited in source order, including the keys and values of
+// hash literals.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+	case *LetStatement:
+		walkIdent(v, n.Name)
+		walkType(v, n.Type)
+		walkExpr(v, n.Value)
+	case *ReturnStatement:
+		walkExpr(v, n.ReturnValue)
+	case *ExpressionStatement:
+		walkExpr(v, n.Expression)
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *DoublePlusStatement:
+		walkIdent(v, n.Name)
+	case *SwitchStatement:
+		walkExpr(v, n.Expression)
+		for _, c := range n.Case {
+			if c != nil {
+				Walk(v, c)
+			}
+		}
+	case *CaseStatement:
+		walkExpr(v, n.Condition)
+		walkStatements(v, n.Statements)
+	case *ImportStatement:
+		if n.Path != nil {
+			Walk(v, n.Path)
+		}
+		walkIdent(v, n.Alias)
+		for _, name := range n.Names {
+			walkIdent(v, name.Name)
+			walkIdent(v, name.Alias)
+		}
+	case *ExportStatement:
+		if n.Statement != nil {
+			Walk(v, n.Statement)
+		}
+
+	case *Identifier, *IntegerLiteral, *FloatLiteral, *Boolean, *StringLiteral:
+		// 子ノードを持たない
+	case *PrefixExpression:
+		walkExpr(v, n.Right)
+	case *InfixExpression:
+		walkExpr(v, n.Left)
+		walkExpr(v, n.Right)
+	case *IfExpression:
+		walkExpr(v, n.Condition)
+		walkBlock(v, n.Consequence)
+		walkBlock(v, n.Alternative)
+	case *ForExpression:
+		walkStmt(v, n.InitStatement)
+		walkExpr(v, n.FinishCondition)
+		walkStmt(v, n.LoopStatement)
+		walkBlock(v, n.Consequence)
+	case *FunctionLiteral:
+		walkIdent(v, n.Name)
+		for i, p := range n.Parameters {
+			walkIdent(v, p)
+			if i < len(n.ParameterTypes) {
+				walkType(v, n.ParameterTypes[i])
+			}
+			if i < len(n.Defaults) {
+				walkExpr(v, n.Defaults[i])
+			}
+		}
+		walkIdent(v, n.Rest)
+		walkType(v, n.RestType)
+		walkType(v, n.ReturnType)
+		walkBlock(v, n.Body)
+	case *CallExpression:
+		walkExpr(v, n.Function)
+		walkExprs(v, n.Arguments)
+	case *ArrayLiteral:
+		walkExprs(v, n.Elements)
+	case *IndexExpression:
+		walkExpr(v, n.Left)
+		walkExpr(v, n.Index)
+	case *HashLiteral:
+		for _, key := range n.OrderedKeys() {
+			walkExpr(v, key)
+			walkExpr(v, n.Pairs[key])
+		}
+	case *DotExpression:
+		walkExpr(v, n.Left)
+		walkIdent(v, n.Name)
+	case *SpreadExpression:
+		walkExpr(v, n.Value)
+
+	case *NamedType:
+		// 子ノードを持たない
+	case *ArrayType:
+		walkType(v, n.Element)
+	case *HashType:
+		walkType(v, n.Key)
+		walkType(v, n.Value)
+	case *FunctionType:
+		for _, p := range n.Parameters {
+			walkType(v, p)
+		}
+		walkType(v, n.Rest)
+		walkType(v, n.Return)
+	}
+
+	v.Visit(nil)
+}
+
+// nil のポインタを持つインターフェース値 (構文エラーで生じる) も飛ばす
+
+func walkIdent(v Visitor, ident *Identifier) {
+	if ident != nil {
+		Walk(v, ident)
+	}
+}
+
+func walkBlock(v Visitor, block *BlockStatement) {
+	if block != nil {
+		Walk(v, block)
+	}
+}
+
+func walkExpr(v Visitor, exp Expression) {
+	if !isNil(exp) {
+		Walk(v, exp)
+	}
+}
+
+func walkStmt(v Visitor, stmt Statement) {
+	if !isNil(stmt) {
+		Walk(v, stmt)
+	}
+}
+
+func walkType(v Visitor, typ TypeExpression) {
+	if !isNil(typ) {
+		Walk(v, typ)
+	}
+}
+
+func walkExprs(v Visitor, exps []Expression) {
+	for _, exp := range exps {
+		walkExpr(v, exp)
+	}
+}
+
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		walkStmt(v, stmt)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,163 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+func ident(name string) *Identifier {
+	return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+}
+
+func integer(value int64) *IntegerLiteral {
+	return &IntegerLiteral{Token: token.Token{Type: token.INT}, Value: value}
+}
+
+func str(value string) *StringLiteral {
+	return &StringLiteral{Token: token.Token{Type: token.STRING, Literal: value}, Value: value}
+}
+
+// names collects the identifiers and integer literals of node in the order
+// Inspect visits them.
+func names(node Node) string {
+	visited := []string{}
+	Inspect(node, func(n Node) bool {
+		switch n := n.(type) {
+		case *Identifier:
+			visited = append(visited, n.Value)
+		case *IntegerLiteral:
+			visited = append(visited, string(rune('0'+n.Value)))
+		case *StringLiteral:
+			visited = append(visited, n.Value)
+		}
+		return true
+	})
+	return strings.Join(visited, " ")
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		node     Node
+		expected string
+	}{
+		{&Program{Statements: []Statement{
+			&LetStatement{Name: ident("a"), Value: integer(1)},
+			&ReturnStatement{ReturnValue: ident("b")},
+			&ExpressionStatement{Expression: &InfixExpression{Left: ident("c"), Operator: "+", Right: &PrefixExpression{Operator: "-", Right: integer(2)}}},
+		}}, "a 1 b c 2"},
+		{&IfExpression{
+			Condition:   ident("a"),
+			Consequence: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: integer(1)}}},
+			Alternative: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: integer(2)}}},
+		}, "a 1 2"},
+		{&ForExpression{
+			InitStatement:   &LetStatement{Name: ident("i"), Value: integer(0)},
+			FinishCondition: &InfixExpression{Left: ident("i"), Operator: "<", Right: integer(3)},
+			LoopStatement:   &DoublePlusStatement{Name: ident("i")},
+			Consequence:     &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: ident("x")}}},
+		}, "i 0 i 3 i x"},
+		{&FunctionLiteral{
+			Name:       ident("f"),
+			Parameters: []*Identifier{ident("a"), ident("b")},
+			Defaults:   []Expression{nil, integer(1)},
+			Rest:       ident("rest"),
+			Body:       &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: ident("a")}}},
+		}, "f a b 1 rest a"},
+		{&CallExpression{Function: ident("f"), Arguments: []Expression{
+			&ArrayLiteral{Elements: []Expression{integer(1), &SpreadExpression{Value: ident("xs")}}},
+			&IndexExpression{Left: ident("a"), Index: integer(2)},
+			&DotExpression{Left: ident("m"), Name: ident("name")},
+		}}, "f 1 xs a 2 m name"},
+		{&HashLiteral{
+			Pairs: map[Expression]Expression{ident("k2"): integer(2), ident("k1"): integer(1), ident("k3"): integer(3)},
+		}, ""},
+		{&SwitchStatement{Expression: ident("s"), Case: []*CaseStatement{
+			{Condition: ident("a"), Statements: []Statement{&ExpressionStatement{Expression: integer(1)}}},
+			{Condition: ident("b"), Statements: []Statement{&ExpressionStatement{Expression: integer(2)}}},
+		}}, "s a 1 b 2"},
+		{&ImportStatement{Path: str("lib"), Names: []*ImportName{{Name: ident("x")}, {Name: ident("y"), Alias: ident("z")}}}, "lib x y z"},
+		{&ExportStatement{Statement: &LetStatement{Name: ident("e"), Value: integer(5)}}, "e 5"},
+	}
+
+	for i, tt := range tests {
+		if hash, ok := tt.node.(*HashLiteral); ok {
+			// Keys の順に辿る
+			k1, k2, k3 := findKey(hash, "k1"), findKey(hash, "k2"), findKey(hash, "k3")
+			hash.Keys = []Expression{k3, k1, k2}
+			tt.expected = "k3 3 k1 1 k2 2"
+		}
+		if got := names(tt.node); got != tt.expected {
+			t.Errorf("tests[%d]: wrong visiting order. got=%q, want=%q", i, got, tt.expected)
+		}
+	}
+}
+
+func findKey(hash *HashLiteral, name string) Expression {
+	for key := range hash.Pairs {
+		if key.(*Identifier).Value == name {
+			return key
+		}
+	}
+	return nil
+}
+
+func TestInspectPrune(t *testing.T) {
+	program := &Program{Statements: []Statement{
+		&LetStatement{Name: ident("f"), Value: &FunctionLiteral{
+			Parameters: []*Identifier{ident("a")},
+			Body:       &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: ident("a")}}},
+		}},
+		&ExpressionStatement{Expression: ident("b")},
+	}}
+
+	visited := []string{}
+	Inspect(program, func(n Node) bool {
+		if id, ok := n.(*Identifier); ok {
+			visited = append(visited, id.Value)
+		}
+		_, isFunction := n.(*FunctionLiteral)
+		return !isFunction
+	})
+
+	if strings.Join(visited, " ") != "f b" {
+		t.Errorf("function body was not skipped. visited=%v", visited)
+	}
+}
+
+type depthVisitor struct {
+	depth    *int
+	maxDepth *int
+}
+
+func (v depthVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		*v.depth--
+		return nil
+	}
+	*v.depth++
+	if *v.depth > *v.maxDepth {
+		*v.maxDepth = *v.depth
+	}
+	return v
+}
+
+func TestWalkCallsVisitNilAfterChildren(t *testing.T) {
+	program := &Program{Statements: []Statement{
+		&ExpressionStatement{Expression: &InfixExpression{Left: integer(1), Operator: "+", Right: integer(2)}},
+		// 構文エラーで生じる nil の子は飛ばす
+		&ReturnStatement{},
+		&LetStatement{Name: ident("x")},
+	}}
+
+	depth, maxDepth := 0, 0
+	Walk(depthVisitor{&depth, &maxDepth}, program)
+
+	if depth != 0 {
+		t.Errorf("Visit(nil) calls unbalanced. depth=%d", depth)
+	}
+	if maxDepth != 4 {
+		t.Errorf("wrong max depth. got=%d, want=4", maxDepth)
+	}
+}
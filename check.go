@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/Bo0km4n/dummy-monkey/checker"
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/repl"
+	"github.com/Bo0km4n/dummy-monkey/resolver"
+)
+
+// checkCommand implements `monkey check path ...`. It reports undefined
+// variables and type errors without running the programs, and returns 1
+// when errors were found and 2 when a file cannot be read or parsed.
+func checkCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	files, err := monkeyFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	status := 0
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 2
+			continue
+		}
+		p := parser.New(lexer.New(string(src)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			for _, msg := range p.Errors() {
+				fmt.Fprintf(stderr, "%s: %s\n", file, msg)
+			}
+			status = 2
+			continue
+		}
+
+		// スクリプトには args が定義されている
+		r := resolver.New(append(evaluator.BuiltinNames(), repl.ArgsVariable)...)
+		r.Resolve(program)
+		c := checker.New()
+		c.Check(program)
+
+		errors := append(r.Errors(), c.Errors()...)
+		for _, msg := range errors {
+			fmt.Fprintf(stdout, "%s: %s\n", file, msg)
+		}
+		if len(errors) > 0 && status == 0 {
+			status = 1
+		}
+	}
+	return status
+}
@@ -0,0 +1,104 @@
+package checker
+
+import "github.com/Bo0km4n/dummy-monkey/ast"
+
+// 組み込み関数の型. 引数の型によって戻り値の型が変わる関数は
+// builtinChecks で呼び出しごとに検査する
+var builtinTypes = map[string]Type{
+	"len":            &Function{Params: []Type{Any}, Required: 1, Return: Int},
+	"puts":           &Function{Rest: Any, Return: Null},
+	"exit":           &Function{Params: []Type{Int}, Return: Null},
+	"first":          &Function{Params: []Type{&Array{Element: Any}}, Required: 1, Return: Any},
+	"last":           &Function{Params: []Type{&Array{Element: Any}}, Required: 1, Return: Any},
+	"rest":           &Function{Params: []Type{&Array{Element: Any}}, Required: 1, Return: &Array{Element: Any}},
+	"push":           &Function{Params: []Type{&Array{Element: Any}, Any}, Required: 2, Return: &Array{Element: Any}},
+	"json_parse":     &Function{Params: []Type{String}, Required: 1, Return: Any},
+	"json_stringify": &Function{Params: []Type{Any}, Required: 1, Rest: Any, Return: String},
+	"regex":          &Function{Params: []Type{String}, Required: 1, Return: Any},
+	"sprintf":        &Function{Params: []Type{String}, Required: 1, Rest: Any, Return: String},
+	"printf":         &Function{Params: []Type{String}, Required: 1, Rest: Any, Return: Null},
+	"assert":         &Function{Params: []Type{Any, String}, Required: 1, Return: Null},
+	"assert_eq":      &Function{Params: []Type{Any, Any, String}, Required: 2, Return: Null},
+	"assert_error":   &Function{Params: []Type{&Function{Return: Any}, String}, Required: 1, Return: Null},
+}
+
+// builtinType returns the type of the builtin function name. Builtins the
+// checker does not know are of type any.
+func builtinType(name string) Type {
+	if typ, ok := builtinTypes[name]; ok {
+		return typ
+	}
+	return Any
+}
+
+type builtinCheck func(c *Checker, call *ast.CallExpression, args []Type) Type
+
+var builtinChecks = map[string]builtinCheck{
+	"len":   checkLen,
+	"first": checkElement("first"),
+	"last":  checkElement("last"),
+	"rest":  checkRest,
+	"push":  checkPush,
+}
+
+func (c *Checker) checkArgCount(call *ast.CallExpression, args []Type, want int) bool {
+	if len(args) != want {
+		c.errorf(call, "wrong number of arguments. got=%d, want=%d", len(args), want)
+		return false
+	}
+	return true
+}
+
+func checkLen(c *Checker, call *ast.CallExpression, args []Type) Type {
+	if !c.checkArgCount(call, args, 1) {
+		return Int
+	}
+	if _, ok := args[0].(*Array); !ok && args[0] != String && args[0] != Any {
+		c.errorf(call, "argument to `len` not supported, got %s", args[0])
+	}
+	return Int
+}
+
+// array checks that the only argument of the builtin name is an array and
+// returns its type.
+func (c *Checker) array(name string, call *ast.CallExpression, arg Type) (*Array, bool) {
+	if arr, ok := arg.(*Array); ok {
+		return arr, true
+	}
+	if arg != Any {
+		c.errorf(call, "argument to `%s` must be array, got %s", name, arg)
+	}
+	return nil, false
+}
+
+func checkElement(name string) builtinCheck {
+	return func(c *Checker, call *ast.CallExpression, args []Type) Type {
+		if !c.checkArgCount(call, args, 1) {
+			return Any
+		}
+		if arr, ok := c.array(name, call, args[0]); ok {
+			return arr.Element
+		}
+		return Any
+	}
+}
+
+func checkRest(c *Checker, call *ast.CallExpression, args []Type) Type {
+	if !c.checkArgCount(call, args, 1) {
+		return &Array{Element: Any}
+	}
+	if arr, ok := c.array("rest", call, args[0]); ok {
+		return arr
+	}
+	return &Array{Element: Any}
+}
+
+func checkPush(c *Checker, call *ast.CallExpression, args []Type) Type {
+	if !c.checkArgCount(call, args, 2) {
+		return &Array{Element: Any}
+	}
+	if arr, ok := c.array("push", call, args[0]); ok {
+		return &Array{Element: join(arr.Element, args[1])}
+	}
+	return &Array{Element: Any}
+}
@@ -0,0 +1,808 @@
+// Package checker infers the types of Monkey programs and reports type
+// errors, such as adding an integer to a string, before the program runs.
+//
+// Type annotations (`let x: int = 5`, `fn(a: int): bool`) are optional.
+// Unannotated variables take the type of their value and unannotated
+// functions return the type of their result. An unannotated parameter
+// takes the type its function body requires of it: in fn(a) { a + 1 } a
+// must be a number, so passing a string to the function is an error.
+// Only operators that run on every call constrain a parameter; those
+// inside if, for, switch or after a return do not. Parameters without
+// such uses, and values whose types are otherwise unknown, are of type
+// any, which is compatible with every type. Errors are only reported when
+// the types involved are known, so unannotated code that runs without
+// errors passes the checker.
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+type scope struct {
+	outer *scope
+	vars  map[string]Type
+}
+
+// function は検査中の関数
+type function struct {
+	result  Type   // 戻り値の型注釈. なければ nil
+	returns []Type // return 文で返す値の型
+
+	// 型注釈のない引数に本体の使われ方から推論した型. 使われていなければ nil,
+	// 使われ方が食い違えば any
+	scope  *scope
+	params map[string]Type
+}
+
+type Checker struct {
+	scope  *scope
+	fn     *function
+	errors []string
+	types  map[ast.Node]Type
+
+	// if や for の中の let は実行されないかもしれないので型を合わせる
+	conditional int
+}
+
+// New returns a checker for programs that run in the global environment.
+// Variables declared by a checked program stay visible to the programs
+// checked after it, as in a REPL session.
+func New() *Checker {
+	c := &Checker{types: map[ast.Node]Type{}}
+	c.scope = &scope{vars: map[string]Type{}}
+	for _, name := range evaluator.BuiltinNames() {
+		c.scope.vars[name] = builtinType(name)
+	}
+	c.open()
+	return c
+}
+
+func (c *Checker) Errors() []string {
+	return c.errors
+}
+
+// TypeOf returns the type the checker inferred for an expression or a
+// declared identifier, or nil if node was not checked.
+func (c *Checker) TypeOf(node ast.Node) Type {
+	return c.types[node]
+}
+
+// Lookup returns the type of the top-level variable name, or nil if it
+// is not declared.
+func (c *Checker) Lookup(name string) Type {
+	return c.scope.vars[name]
+}
+
+func (c *Checker) errorf(node ast.Node, format string, a ...interface{}) {
+	tok := position(node)
+	c.errors = append(c.errors, fmt.Sprintf(format, a...)+
+		fmt.Sprintf(" (line %d, column %d)", tok.Line, tok.Column))
+}
+
+// position returns the token node starts at.
+func position(node ast.Node) token.Token {
+	switch node := node.(type) {
+	case *ast.LetStatement:
+		return node.Token
+	case *ast.ReturnStatement:
+		return node.Token
+	case *ast.ExpressionStatement:
+		return node.Token
+	case *ast.BlockStatement:
+		return node.Token
+	case *ast.DoublePlusStatement:
+		return node.Token
+	case *ast.SwitchStatement:
+		return node.Token
+	case *ast.CaseStatement:
+		return node.Token
+	case *ast.ImportStatement:
+		return node.Token
+	case *ast.ExportStatement:
+		return node.Token
+	case *ast.Identifier:
+		return node.Token
+	case *ast.IntegerLiteral:
+		return node.Token
+	case *ast.FloatLiteral:
+		return node.Token
+	case *ast.StringLiteral:
+		return node.Token
+	case *ast.Boolean:
+		return node.Token
+	case *ast.PrefixExpression:
+		return node.Token
+	case *ast.InfixExpression:
+		return node.Token
+	case *ast.IfExpression:
+		return node.Token
+	case *ast.ForExpression:
+		return node.Token
+	case *ast.FunctionLiteral:
+		return node.Token
+	case *ast.CallExpression:
+		return node.Token
+	case *ast.ArrayLiteral:
+		return node.Token
+	case *ast.IndexExpression:
+		return node.Token
+	case *ast.HashLiteral:
+		return node.Token
+	case *ast.DotExpression:
+		return node.Token
+	case *ast.SpreadExpression:
+		return node.Token
+	case *ast.NamedType:
+		return node.Token
+	case *ast.ArrayType:
+		return node.Token
+	case *ast.HashType:
+		return node.Token
+	case *ast.FunctionType:
+		return node.Token
+	}
+	return token.Token{}
+}
+
+// Check infers the types in program and records the errors it finds.
+func (c *Checker) Check(program *ast.Program) {
+	c.statements(program.Statements)
+}
+
+// global returns the type of name in the outermost scope, which holds
+// the builtin functions.
+func (s *scope) global(name string) Type {
+	for s.outer != nil {
+		s = s.outer
+	}
+	return s.vars[name]
+}
+
+func (c *Checker) open() {
+	c.scope = &scope{outer: c.scope, vars: map[string]Type{}}
+}
+
+func (c *Checker) close() {
+	c.scope = c.scope.outer
+}
+
+func (c *Checker) declare(ident *ast.Identifier, typ Type) {
+	if old, ok := c.scope.vars[ident.Value]; ok && c.conditional > 0 {
+		typ = join(old, typ)
+	}
+	c.scope.vars[ident.Value] = typ
+	c.types[ident] = typ
+	// 引数と同じ名前で宣言し直した変数は引数ではない
+	if c.fn != nil && c.scope == c.fn.scope {
+		delete(c.fn.params, ident.Value)
+	}
+}
+
+func (c *Checker) lookup(name string) Type {
+	for s := c.scope; s != nil; s = s.outer {
+		if typ, ok := s.vars[name]; ok {
+			return typ
+		}
+	}
+	// 未定義の変数は resolver が報告する
+	return Any
+}
+
+// constrain records that exp, if it is an unannotated parameter of the
+// function being checked, must be of type typ. Uses that may not run when
+// the function is called do not constrain the parameter.
+func (c *Checker) constrain(exp ast.Expression, typ Type) {
+	ident, ok := exp.(*ast.Identifier)
+	if !ok || c.fn == nil || c.conditional > 0 || len(c.fn.returns) > 0 {
+		return
+	}
+	old, ok := c.fn.params[ident.Value]
+	if !ok {
+		return
+	}
+	// 内側のスコープで同じ名前の変数が宣言されていれば引数ではない
+	for s := c.scope; s != c.fn.scope; s = s.outer {
+		if _, ok := s.vars[ident.Value]; ok {
+			return
+		}
+	}
+	c.fn.params[ident.Value] = join(old, typ)
+}
+
+// operand returns the type an operand of op must have when the other
+// operand is of type other, or nil if any type may do.
+func operand(op string, other Type) Type {
+	switch {
+	case isNumber(other):
+		// 整数と浮動小数点数は混ぜて計算できる
+		if op == "+" || op == "-" || op == "*" || op == "/" || op == "%" || op == "<" || op == ">" {
+			return Float
+		}
+	case other == String:
+		if op == "+" {
+			return String
+		}
+	case other == Bool:
+		if op == "&&" {
+			return Bool
+		}
+	}
+	return nil
+}
+
+// typeOf converts a type annotation to a type.
+func (c *Checker) typeOf(node ast.TypeExpression) Type {
+	switch node := node.(type) {
+	case *ast.NamedType:
+		if typ, ok := basicTypes[node.Name]; ok {
+			return typ
+		}
+		c.errorf(node, "unknown type: %s", node.Name)
+		return Any
+	case *ast.ArrayType:
+		return &Array{Element: c.typeOf(node.Element)}
+	case *ast.HashType:
+		key := c.typeOf(node.Key)
+		if !isHashable(key) {
+			c.errorf(node, "unusable as hash key: %s", key)
+		}
+		return &Hash{Key: key, Value: c.typeOf(node.Value)}
+	case *ast.FunctionType:
+		fn := &Function{Return: Any}
+		for _, p := range node.Parameters {
+			fn.Params = append(fn.Params, c.typeOf(p))
+		}
+		fn.Required = len(fn.Params)
+		if node.Rest != nil {
+			fn.Rest = c.restElement(node.Rest)
+		}
+		if node.Return != nil {
+			fn.Return = c.typeOf(node.Return)
+		}
+		return fn
+	}
+	return Any
+}
+
+// restElement returns the element type of a rest parameter annotation,
+// which must be an array type.
+func (c *Checker) restElement(node ast.TypeExpression) Type {
+	switch typ := c.typeOf(node).(type) {
+	case *Array:
+		return typ.Element
+	case *Basic:
+		if typ == Any {
+			return Any
+		}
+	}
+	c.errorf(node, "rest parameter must be of array type, got %s", node)
+	return Any
+}
+
+// expect reports an error if a value of type typ cannot be used as want.
+// Array and hash literals are checked element by element, so that
+// [1, "a"] is reported as a string where an int is expected rather than
+// being accepted as [any].
+func (c *Checker) expect(node ast.Node, typ, want Type, context string) {
+	switch exp := node.(type) {
+	case *ast.ArrayLiteral:
+		if want, ok := want.(*Array); ok {
+			for _, el := range exp.Elements {
+				if spread, ok := el.(*ast.SpreadExpression); ok {
+					c.expect(spread.Value, c.types[spread.Value], want, context)
+				} else {
+					c.expect(el, c.types[el], want.Element, context)
+				}
+			}
+			return
+		}
+	case *ast.HashLiteral:
+		if want, ok := want.(*Hash); ok {
+			for _, key := range exp.OrderedKeys() {
+				c.expect(key, c.types[key], want.Key, context)
+				c.expect(exp.Pairs[key], c.types[exp.Pairs[key]], want.Value, context)
+			}
+			return
+		}
+	}
+
+	if typ != nil && !Assignable(typ, want) {
+		c.errorf(node, "cannot use %s as %s in %s", typ, want, context)
+	}
+}
+
+// statements checks a statement list and returns the type of its value:
+// the value of the last statement, or nil if the list always returns.
+func (c *Checker) statements(stmts []ast.Statement) Type {
+	var result Type = Null
+	for _, stmt := range stmts {
+		result = c.statement(stmt)
+	}
+	return result
+}
+
+func (c *Checker) statement(stmt ast.Statement) Type {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		if stmt.Expression == nil {
+			return Null
+		}
+		return c.expr(stmt.Expression)
+	case *ast.LetStatement:
+		return c.let(stmt)
+	case *ast.ExportStatement:
+		return c.let(stmt.Statement)
+	case *ast.ReturnStatement:
+		typ := c.expr(stmt.ReturnValue)
+		if c.fn != nil {
+			if c.fn.result != nil {
+				c.expect(stmt.ReturnValue, typ, c.fn.result, "return value")
+			}
+			c.fn.returns = append(c.fn.returns, typ)
+		}
+		return nil
+	case *ast.BlockStatement:
+		return c.statements(stmt.Statements)
+	case *ast.DoublePlusStatement:
+		switch typ := c.ident(stmt.Name); typ {
+		case Int:
+		case Any:
+			c.constrain(stmt.Name, Int)
+		default:
+			c.errorf(stmt, "unknown operator: ++%s", typ)
+		}
+		return Int
+	case *ast.SwitchStatement:
+		return c.switchStatement(stmt)
+	case *ast.ImportStatement:
+		c.importStatement(stmt)
+		return Null
+	}
+	return Any
+}
+
+func (c *Checker) let(stmt *ast.LetStatement) Type {
+	var want Type
+	if stmt.Type != nil {
+		want = c.typeOf(stmt.Type)
+	}
+
+	// let f = fn() {...} は本体から f を参照できるように先に宣言する
+	var typ Type
+	if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok && fn.Name == nil {
+		typ = c.function(fn, func(sig *Function) {
+			if want == nil {
+				c.declare(stmt.Name, sig)
+			} else {
+				c.declare(stmt.Name, want)
+			}
+		})
+		c.types[fn] = typ
+	} else {
+		typ = c.expr(stmt.Value)
+	}
+
+	if want != nil {
+		c.expect(stmt.Value, typ, want, "declaration of "+stmt.Name.Value)
+		typ = want
+	}
+	c.declare(stmt.Name, typ)
+	return typ
+}
+
+func (c *Checker) importStatement(stmt *ast.ImportStatement) {
+	if stmt.Names != nil {
+		for _, name := range stmt.Names {
+			c.declare(name.Binding(), Any)
+		}
+		return
+	}
+	if stmt.Alias != nil {
+		c.declare(stmt.Alias, Any)
+		return
+	}
+	// import "path" の束縛名は resolver と同じく拡張子を除いたファイル名
+	base := filepath.Base(stmt.Path.Value)
+	c.scope.vars[strings.TrimSuffix(base, filepath.Ext(base))] = Any
+}
+
+func (c *Checker) switchStatement(stmt *ast.SwitchStatement) Type {
+	if stmt.Expression != nil {
+		c.expr(stmt.Expression)
+	}
+	c.conditional++
+	defer func() { c.conditional-- }()
+
+	for _, cs := range stmt.Case {
+		if typ := c.expr(cs.Condition); typ != Bool && typ != Any {
+			c.errorf(cs, "case condition must be bool, got %s", typ)
+		}
+		c.statements(cs.Statements)
+	}
+	return Any
+}
+
+func (c *Checker) expr(exp ast.Expression) Type {
+	if exp == nil {
+		return Any
+	}
+	typ := c.infer(exp)
+	c.types[exp] = typ
+	return typ
+}
+
+func (c *Checker) infer(exp ast.Expression) Type {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+	case *ast.FloatLiteral:
+		return Float
+	case *ast.StringLiteral:
+		return String
+	case *ast.Boolean:
+		return Bool
+	case *ast.Identifier:
+		return c.ident(exp)
+	case *ast.PrefixExpression:
+		return c.prefix(exp)
+	case *ast.InfixExpression:
+		return c.infix(exp)
+	case *ast.IfExpression:
+		c.expr(exp.Condition)
+		c.conditional++
+		defer func() { c.conditional-- }()
+		cons := c.statements(exp.Consequence.Statements)
+		var alt Type = Null
+		if exp.Alternative != nil {
+			alt = c.statements(exp.Alternative.Statements)
+		}
+		if cons == nil && alt == nil {
+			return Any
+		}
+		return join(cons, alt)
+	case *ast.ForExpression:
+		return c.forExpression(exp)
+	case *ast.FunctionLiteral:
+		return c.function(exp, nil)
+	case *ast.CallExpression:
+		return c.call(exp)
+	case *ast.ArrayLiteral:
+		var elem Type
+		for _, el := range exp.Elements {
+			elem = join(elem, c.element(el))
+		}
+		if elem == nil {
+			elem = Any
+		}
+		return &Array{Element: elem}
+	case *ast.HashLiteral:
+		return c.hash(exp)
+	case *ast.IndexExpression:
+		return c.index(exp)
+	case *ast.DotExpression:
+		return c.dot(exp)
+	case *ast.SpreadExpression:
+		c.expr(exp.Value)
+		return Any
+	}
+	return Any
+}
+
+func (c *Checker) ident(ident *ast.Identifier) Type {
+	typ := c.lookup(ident.Value)
+	c.types[ident] = typ
+	return typ
+}
+
+// element returns the type of an array element or argument, which is the
+// element type of the array for ...spread.
+func (c *Checker) element(exp ast.Expression) Type {
+	spread, ok := exp.(*ast.SpreadExpression)
+	if !ok {
+		return c.expr(exp)
+	}
+	switch typ := c.expr(spread.Value).(type) {
+	case *Array:
+		return typ.Element
+	case *Basic:
+		if typ == Any {
+			return Any
+		}
+	}
+	c.errorf(spread, "cannot spread %s, want array", c.types[spread.Value])
+	return Any
+}
+
+func (c *Checker) prefix(exp *ast.PrefixExpression) Type {
+	right := c.expr(exp.Right)
+	switch exp.Operator {
+	case "!":
+		return Bool
+	case "-":
+		if right == Any {
+			c.constrain(exp.Right, Float)
+		}
+		if isNumber(right) || right == Any {
+			return right
+		}
+	}
+	c.errorf(exp, "unknown operator: %s%s", exp.Operator, right)
+	return Any
+}
+
+// infix follows the rules of evalInfixExpression.
+func (c *Checker) infix(exp *ast.InfixExpression) Type {
+	left := c.expr(exp.Left)
+	right := c.expr(exp.Right)
+	op := exp.Operator
+
+	comparison := op == "<" || op == ">" || op == "==" || op == "!="
+	switch {
+	case left == Any || right == Any:
+		if want := operand(op, right); want != nil && left == Any {
+			c.constrain(exp.Left, want)
+		}
+		if want := operand(op, left); want != nil && right == Any {
+			c.constrain(exp.Right, want)
+		}
+		if comparison || op == "&&" {
+			return Bool
+		}
+		return Any
+	case isNumber(left) && isNumber(right):
+		switch {
+		case comparison:
+			return Bool
+		case op == "+" || op == "-" || op == "*" || op == "/" || op == "%":
+			if left == Int && right == Int {
+				return Int
+			}
+			return Float
+		}
+	case left == String && right == String:
+		if op == "+" {
+			return String
+		}
+	case op == "==" || op == "!=":
+		return Bool
+	case op == "&&" && left == Bool && right == Bool:
+		return Bool
+	case !Identical(left, right):
+		c.errorf(exp, "type mismatch: %s %s %s", left, op, right)
+		return Any
+	}
+	c.errorf(exp, "unknown operator: %s %s %s", left, op, right)
+	return Any
+}
+
+func (c *Checker) forExpression(exp *ast.ForExpression) Type {
+	c.open()
+	defer c.close()
+	c.conditional++
+	defer func() { c.conditional-- }()
+
+	if exp.InitStatement != nil {
+		c.statement(exp.InitStatement)
+	}
+	if typ := c.expr(exp.FinishCondition); typ != Bool && typ != Any {
+		c.errorf(exp, "for condition must be bool, got %s", typ)
+	}
+	if exp.LoopStatement != nil {
+		c.statement(exp.LoopStatement)
+	}
+	c.statements(exp.Consequence.Statements)
+	return Any
+}
+
+// function checks a function literal and returns its type. declare, if
+// not nil, is called with the signature before the body is checked so
+// that the body can refer to the function; an unannotated result is any
+// until the body has been checked.
+func (c *Checker) function(fn *ast.FunctionLiteral, declare func(*Function)) Type {
+	sig := &Function{Return: Any}
+	if fn.ReturnType != nil {
+		sig.Return = c.typeOf(fn.ReturnType)
+	}
+
+	// 引数の型は型注釈, なければデフォルト値の型
+	for i := range fn.Parameters {
+		var typ Type = Any
+		if i < len(fn.ParameterTypes) && fn.ParameterTypes[i] != nil {
+			typ = c.typeOf(fn.ParameterTypes[i])
+		} else if i < len(fn.Defaults) && fn.Defaults[i] != nil {
+			typ = nil
+		}
+		if i >= len(fn.Defaults) || fn.Defaults[i] == nil {
+			sig.Required = i + 1
+		}
+		sig.Params = append(sig.Params, typ)
+	}
+	if fn.Rest != nil {
+		sig.Rest = Any
+		if fn.RestType != nil {
+			sig.Rest = c.restElement(fn.RestType)
+		}
+	}
+
+	if fn.Name != nil {
+		c.declare(fn.Name, sig)
+	}
+	if declare != nil {
+		declare(sig)
+	}
+
+	c.open()
+	defer c.close()
+	outer, outerConditional := c.fn, c.conditional
+	c.fn = &function{scope: c.scope}
+	c.conditional = 0
+	defer func() { c.fn, c.conditional = outer, outerConditional }()
+
+	// デフォルト値は関数の環境で評価されるので前の引数を参照できる
+	for i, p := range fn.Parameters {
+		if i < len(fn.Defaults) && fn.Defaults[i] != nil {
+			typ := c.expr(fn.Defaults[i])
+			if sig.Params[i] == nil {
+				sig.Params[i] = typ
+			} else {
+				c.expect(fn.Defaults[i], typ, sig.Params[i], "default value of "+p.Value)
+			}
+		}
+		c.declare(p, sig.Params[i])
+	}
+	if fn.Rest != nil {
+		c.declare(fn.Rest, &Array{Element: sig.Rest})
+	}
+
+	c.fn.params = map[string]Type{}
+	for i, p := range fn.Parameters {
+		if sig.Params[i] == Any && (i >= len(fn.ParameterTypes) || fn.ParameterTypes[i] == nil) {
+			c.fn.params[p.Value] = nil
+		}
+	}
+
+	if fn.ReturnType != nil {
+		c.fn.result = sig.Return
+	}
+	result := c.statements(fn.Body.Statements)
+
+	// 本体は any として検査したので, 推論した型は呼び出し側の検査だけに使う
+	for i, p := range fn.Parameters {
+		if typ, ok := c.fn.params[p.Value]; ok && typ != nil {
+			sig.Params[i] = typ
+		}
+	}
+	if fn.ReturnType != nil {
+		c.expect(lastExpression(fn.Body), result, sig.Return, "return value")
+		return sig
+	}
+
+	// 型注釈がなければ return 文の値と最後の式の値から推論する
+	for _, typ := range c.fn.returns {
+		result = join(result, typ)
+	}
+	if result == nil {
+		result = Null
+	}
+	sig.Return = result
+	return sig
+}
+
+// lastExpression returns the node whose value a block evaluates to, for
+// error positions.
+func lastExpression(block *ast.BlockStatement) ast.Node {
+	if n := len(block.Statements); n > 0 {
+		if es, ok := block.Statements[n-1].(*ast.ExpressionStatement); ok && es.Expression != nil {
+			return es.Expression
+		}
+		return block.Statements[n-1]
+	}
+	return block
+}
+
+func (c *Checker) call(exp *ast.CallExpression) Type {
+	callee := c.expr(exp.Function)
+
+	args := []Type{}
+	spread := false
+	for _, arg := range exp.Arguments {
+		if _, ok := arg.(*ast.SpreadExpression); ok {
+			spread = true
+		}
+		args = append(args, c.element(arg))
+	}
+
+	if ident, ok := exp.Function.(*ast.Identifier); ok {
+		if check, ok := builtinChecks[ident.Value]; ok && callee == c.scope.global(ident.Value) {
+			return check(c, exp, args)
+		}
+	}
+
+	switch fn := callee.(type) {
+	case *Function:
+		name := exp.Function.String()
+		if !spread {
+			if len(args) > len(fn.Params) && fn.Rest == nil {
+				c.errorf(exp, "too many arguments in call to %s. got=%d, want=%d", name, len(args), len(fn.Params))
+			} else if len(args) < fn.Required {
+				c.errorf(exp, "not enough arguments in call to %s. got=%d, want=%d", name, len(args), fn.Required)
+			}
+		}
+		for i, arg := range exp.Arguments {
+			want := fn.param(i)
+			if want == nil || spread {
+				continue
+			}
+			c.expect(arg, args[i], want, fmt.Sprintf("argument %d to %s", i+1, name))
+		}
+		return fn.Return
+	case *Basic:
+		if fn == Any {
+			return Any
+		}
+	}
+	c.errorf(exp, "cannot call non-function %s", callee)
+	return Any
+}
+
+func (c *Checker) hash(exp *ast.HashLiteral) Type {
+	var key, value Type
+	for _, k := range exp.OrderedKeys() {
+		typ := c.expr(k)
+		if !isHashable(typ) {
+			c.errorf(k, "unusable as hash key: %s", typ)
+		}
+		key = join(key, typ)
+		value = join(value, c.expr(exp.Pairs[k]))
+	}
+	if key == nil {
+		return &Hash{Key: Any, Value: Any}
+	}
+	return &Hash{Key: key, Value: value}
+}
+
+// index follows evalIndexExpression. Indexes out of range and missing keys
+// give null at run time; the checker assumes they are present.
+func (c *Checker) index(exp *ast.IndexExpression) Type {
+	left := c.expr(exp.Left)
+	index := c.expr(exp.Index)
+
+	switch left := left.(type) {
+	case *Array:
+		if index != Int && index != Any {
+			c.errorf(exp, "array index must be int, got %s", index)
+		}
+		return left.Element
+	case *Hash:
+		c.expect(exp.Index, index, left.Key, "hash index")
+		return left.Value
+	case *Basic:
+		if left == Any {
+			return Any
+		}
+	}
+	c.errorf(exp, "index operator not supported: %s", left)
+	return Any
+}
+
+func (c *Checker) dot(exp *ast.DotExpression) Type {
+	switch left := c.expr(exp.Left).(type) {
+	case *Hash:
+		c.expect(exp.Name, String, left.Key, "member access")
+		return left.Value
+	case *Basic:
+		if left == Any {
+			return Any
+		}
+		c.errorf(exp, "member access not supported: %s.%s", left, exp.Name.Value)
+	default:
+		c.errorf(exp, "member access not supported: %s.%s", left, exp.Name.Value)
+	}
+	return Any
+}
@@ -0,0 +1,235 @@
+package checker
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors: %v", len(p.Errors()), p.Errors())
+	}
+	return program
+}
+
+func TestInfer(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5;", "int"},
+		{"let x = 5 * 2.0;", "float"},
+		{`let x = "a" + "b";`, "string"},
+		{"let x = 1 < 2 && !true;", "bool"},
+		{"let x: float = 1;", "float"},
+		{"let x = [1, 2.5];", "[float]"},
+		{`let x = [1, "a"];`, "[any]"},
+		{"let x = [];", "[any]"},
+		{"let x = [[1], [2, 3]][0];", "[int]"},
+		{`let x = {"a": 1, "b": 2};`, `{string: int}`},
+		{`let x = {"a": 1}["a"];`, "int"},
+		{`let x = {"a": [true]}.a;`, "[bool]"},
+		{"let x = fn(a, b) { a + b };", "fn(any, any): any"},
+		{"let x = fn(a: int, b: int) { a + b };", "fn(int, int): int"},
+		{"let x = fn(a, b = 2) { b };", "fn(any, int): int"},
+		{"let x = fn(...xs: [string]) { xs };", "fn(...[string]): [string]"},
+		{"let x = fn(n: int) { if (n > 0) { return \"pos\"; } \"neg\" };", "fn(int): string"},
+		{"let x = fn(n: int) { if (n > 0) { 1 } else { 2.0 } };", "fn(int): float"},
+		{"let x = fn(n: int) { if (n > 0) { 1 } };", "fn(int): any"},
+		{"let x = fn() { };", "fn(): null"},
+		{"let f = fn(n: int): int { n }; let x = f(1);", "int"},
+		{"fn fact(n: int): int { if (n < 2) { return 1; } n * fact(n - 1) }; let x = fact;", "fn(int): int"},
+		{"let x = len([1]) + first([2]);", "int"},
+		{"let x = push([1], 2.0);", "[float]"},
+		{"let x = rest([\"a\"]);", "[string]"},
+		{`let x = sprintf("%d", 1);`, "string"},
+		{"let x = 1; if (true) { let x = \"s\"; }", "any"},
+		{"let x = 1; let x = \"s\";", "string"},
+		{"let x = undefined_variable;", "any"},
+		{"let f: fn(int): int = fn(a) { a }; let x = f;", "fn(int): int"},
+		{"let x = fn(a, b, c) { a * 2; b + \"!\"; ++c; };", "fn(float, string, int): int"},
+		{"let x = fn(a, b) { a && b; a && true };", "fn(bool, any): bool"},
+		{"let x = fn(a, b) { if (a > 0) { b + 1 } };", "fn(float, any): any"},
+		{"let x = fn(a) { a + 1; a + \"!\" };", "fn(any): any"},
+	}
+
+	for _, tt := range tests {
+		c := New()
+		c.Check(parse(t, tt.input))
+		if len(c.Errors()) != 0 {
+			t.Errorf("%q: unexpected errors: %v", tt.input, c.Errors())
+			continue
+		}
+		typ := c.Lookup("x")
+		if typ == nil {
+			t.Errorf("%q: x is not declared", tt.input)
+			continue
+		}
+		if typ.String() != tt.expected {
+			t.Errorf("%q: wrong type. got=%s, want=%s", tt.input, typ, tt.expected)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`1 + "a"`, []string{"type mismatch: int + string (line 1, column 3)"}},
+		{`let a = 1; let b = "x"; a + b`, []string{"type mismatch: int + string (line 1, column 27)"}},
+		{`"a" - "b"`, []string{"unknown operator: string - string (line 1, column 5)"}},
+		{`-"a"`, []string{"unknown operator: -string (line 1, column 1)"}},
+		{`true + false`, []string{"unknown operator: bool + bool (line 1, column 6)"}},
+		{`let s = "a"; ++s;`, []string{"unknown operator: ++string (line 1, column 14)"}},
+		{`let x: int = "a";`, []string{"cannot use string as int in declaration of x (line 1, column 14)"}},
+		{`let x: [int] = [1, "a"];`, []string{"cannot use string as int in declaration of x (line 1, column 20)"}},
+		{`let x: [[int]] = [[1], [2.5]];`, []string{"cannot use float as int in declaration of x (line 1, column 25)"}},
+		{`let x: {string: int} = {1: 1};`, []string{"cannot use int as string in declaration of x (line 1, column 25)"}},
+		{`let f = fn(xs: [string]) { xs }; f(["a", 1]);`, []string{"cannot use int as string in argument 1 to f (line 1, column 42)"}},
+		{`let x: foo = 1;`, []string{"unknown type: foo (line 1, column 8)"}},
+		{`let f = fn(a: int, b: string) { a }; f("a", "b");`, []string{"cannot use string as int in argument 1 to f (line 1, column 40)"}},
+		{`let f = fn(a: int) { a }; f(1, 2);`, []string{"too many arguments in call to f. got=2, want=1 (line 1, column 28)"}},
+		{`let f = fn(a, b = 1) { a }; f();`, []string{"not enough arguments in call to f. got=0, want=1 (line 1, column 30)"}},
+		{`let f = fn(...xs: [int]) { xs }; f(1, "a");`, []string{"cannot use string as int in argument 2 to f (line 1, column 39)"}},
+		{`let f = fn(a: int = "x") { a };`, []string{"cannot use string as int in default value of a (line 1, column 21)"}},
+		{`let f = fn(): int { "a" };`, []string{"cannot use string as int in return value (line 1, column 21)"}},
+		{`let f = fn(): int { if (true) { return "a"; } 1 };`, []string{"cannot use string as int in return value (line 1, column 40)"}},
+		{`let f = fn(): string { return 1; };`, []string{"cannot use int as string in return value (line 1, column 31)"}},
+		{`let g: fn(int): int = fn(a: string) { 1 };`, []string{"cannot use fn(string): int as fn(int): int in declaration of g (line 1, column 23)"}},
+		{`let g: fn(int): int = fn(a, b) { 1 };`, []string{"cannot use fn(any, any): int as fn(int): int in declaration of g (line 1, column 23)"}},
+		{`let n = 1; n(2)`, []string{"cannot call non-function int (line 1, column 13)"}},
+		{`let a = [1]; a["x"]`, []string{"array index must be int, got string (line 1, column 15)"}},
+		{`let h = {"a": 1}; h[1]`, []string{"cannot use int as string in hash index (line 1, column 21)"}},
+		{`1[0]`, []string{"index operator not supported: int (line 1, column 2)"}},
+		{`{[1]: 2}`, []string{"unusable as hash key: [int] (line 1, column 2)"}},
+		{`let n = 1; n.name`, []string{"member access not supported: int.name (line 1, column 13)"}},
+		{`len(1)`, []string{"argument to `len` not supported, got int (line 1, column 4)"}},
+		{`len([1], [2])`, []string{"wrong number of arguments. got=2, want=1 (line 1, column 4)"}},
+		{`first("abc")`, []string{"argument to `first` must be array, got string (line 1, column 6)"}},
+		{`let x = [1, ...2];`, []string{"cannot spread int, want array (line 1, column 13)"}},
+		{`for (let i = 0; 10; ++i) { i }`, []string{"for condition must be bool, got int (line 1, column 1)"}},
+		{`switch { case 1: break; }`, []string{"case condition must be bool, got int (line 1, column 10)"}},
+		{`let f = fn(...xs: int) { xs };`, []string{"rest parameter must be of array type, got int (line 1, column 19)"}},
+		{`let x: {[int]: int} = {};`, []string{"unusable as hash key: [int] (line 1, column 8)"}},
+		{`let len = fn(a: int) { a }; len("a")`, []string{"cannot use string as int in argument 1 to len (line 1, column 33)"}},
+		{`let f = fn(a) { a + 1 }; f("x");`, []string{"cannot use string as float in argument 1 to f (line 1, column 28)"}},
+		{`let f = fn(s) { s + "!" }; f(1);`, []string{"cannot use int as string in argument 1 to f (line 1, column 30)"}},
+		{`let f = fn(n) { -n }; f([1]);`, []string{"cannot use [int] as float in argument 1 to f (line 1, column 25)"}},
+		{`let apply = fn(g: fn(string): any) { g }; apply(fn(a) { a * 2 });`, []string{"cannot use fn(float): any as fn(string): any in argument 1 to apply (line 1, column 49)"}},
+	}
+
+	for _, tt := range tests {
+		c := New()
+		c.Check(parse(t, tt.input))
+		errors := c.Errors()
+		if len(errors) != len(tt.expected) {
+			t.Errorf("%q: wrong number of errors. got=%q, want=%q", tt.input, errors, tt.expected)
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errors[i] != msg {
+				t.Errorf("%q: wrong error. got=%q, want=%q", tt.input, errors[i], msg)
+			}
+		}
+	}
+}
+
+// 型注釈のないコードで実行時に型エラーにならないものは検査を通る
+func TestUnannotatedCodePasses(t *testing.T) {
+	inputs := []string{
+		"let add = fn(a, b) { a + b }; add(1, 2); add(\"a\", \"b\");",
+		"let f = fn(x) { x.name }; f({\"name\": 1});",
+		"let apply = fn(f, x) { f(x) }; apply(fn(n) { n * 2 }, 3);",
+		"let xs = []; let xs = push(xs, 1); xs[0] + 1;",
+		"let f = fn(a, ...rest) { len(rest) }; f(1, 2, 3); f(...[1, 2]);",
+		"import \"strings\"; strings.upper(\"a\");",
+		"let h = json_parse(\"{}\"); h.a + 1;",
+		"let f = fn(a) { a + 1 }; f(1); f(2.5);",
+		"let f = fn(a, s) { if (s) { return a + 1; } a + \"!\" }; f(1, true); f(\"a\", false);",
+		"let f = fn(a) { let a = \"s\"; a + \"!\" }; f(1);",
+		"let f = fn(a) { for (let i = 0; i < 1; ++i) { let a = \"s\"; a + \"!\" } }; f(1);",
+		"let f = fn(a) { fn() { a + 1 } }; f(\"a\");",
+	}
+
+	files, err := filepath.Glob("../testdata/*.monkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(src))
+	}
+
+	for _, input := range inputs {
+		c := New()
+		c.Check(parse(t, input))
+		if len(c.Errors()) != 0 {
+			t.Errorf("%q: unexpected errors: %v", input, c.Errors())
+		}
+	}
+}
+
+func TestCheckerKeepsGlobals(t *testing.T) {
+	c := New()
+	c.Check(parse(t, "let n: int = 1;"))
+	c.Check(parse(t, `n + "a"`))
+	if len(c.Errors()) != 1 || c.Errors()[0] != "type mismatch: int + string (line 1, column 3)" {
+		t.Errorf("wrong errors. got=%q", c.Errors())
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	program := parse(t, "let f = fn(a: int) { [a] }; f(1)[0];")
+	c := New()
+	c.Check(program)
+
+	stmt := program.Statements[1].(*ast.ExpressionStatement)
+	index := stmt.Expression.(*ast.IndexExpression)
+	if typ := c.TypeOf(index.Left); typ == nil || typ.String() != "[int]" {
+		t.Errorf("wrong type of f(1). got=%v", typ)
+	}
+	if typ := c.TypeOf(index); typ == nil || typ.String() != "int" {
+		t.Errorf("wrong type of f(1)[0]. got=%v", typ)
+	}
+}
+
+func TestAssignable(t *testing.T) {
+	fn := func(ret Type, params ...Type) *Function {
+		return &Function{Params: params, Required: len(params), Return: ret}
+	}
+	tests := []struct {
+		from, to Type
+		expected bool
+	}{
+		{Int, Float, true},
+		{Float, Int, false},
+		{Any, Int, true},
+		{String, Any, true},
+		{&Array{Element: Int}, &Array{Element: Float}, true},
+		{&Array{Element: String}, &Array{Element: Int}, false},
+		{&Hash{Key: String, Value: Int}, &Hash{Key: String, Value: Any}, true},
+		{fn(Int, Float), fn(Int, Int), true},
+		{fn(Int, Int), fn(Int, Float), false},
+		{fn(Int), fn(Int, Int), false},
+		{&Function{Params: []Type{Int, Int}, Required: 1, Return: Int}, fn(Int, Int), true},
+		{&Function{Rest: Int, Return: Int}, fn(Int, Int, Int), true},
+		{&Function{Rest: String, Return: Int}, fn(Int, Int), false},
+	}
+
+	for _, tt := range tests {
+		if got := Assignable(tt.from, tt.to); got != tt.expected {
+			t.Errorf("Assignable(%s, %s) = %t, want %t", tt.from, tt.to, got, tt.expected)
+		}
+	}
+}
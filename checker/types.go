@@ -0,0 +1,184 @@
+package checker
+
+import "strings"
+
+// Type is the static type of a Monkey value.
+type Type interface {
+	String() string
+}
+
+// Basic is a type without components.
+type Basic struct {
+	name string
+}
+
+func (b *Basic) String() string { return b.name }
+
+var (
+	Int    = &Basic{"int"}
+	Float  = &Basic{"float"}
+	String = &Basic{"string"}
+	Bool   = &Basic{"bool"}
+	Null   = &Basic{"null"}
+
+	// Any is the type of values the checker knows nothing about, such as
+	// parameters the body does not constrain. It is compatible with every type.
+	Any = &Basic{"any"}
+)
+
+var basicTypes = map[string]Type{
+	"int":    Int,
+	"float":  Float,
+	"string": String,
+	"bool":   Bool,
+	"null":   Null,
+	"any":    Any,
+}
+
+// Array is the type of arrays whose elements are all of type Element.
+type Array struct {
+	Element Type
+}
+
+func (a *Array) String() string { return "[" + a.Element.String() + "]" }
+
+// Hash is the type of hashes from Key to Value.
+type Hash struct {
+	Key   Type
+	Value Type
+}
+
+func (h *Hash) String() string { return "{" + h.Key.String() + ": " + h.Value.String() + "}" }
+
+// Function is the type of functions. The first Required parameters have
+// no default value; Rest is the element type of the rest parameter, or nil.
+type Function struct {
+	Params   []Type
+	Required int
+	Rest     Type
+	Return   Type
+}
+
+func (f *Function) String() string {
+	params := []string{}
+	for _, p := range f.Params {
+		params = append(params, p.String())
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+(&Array{Element: f.Rest}).String())
+	}
+	return "fn(" + strings.Join(params, ", ") + "): " + f.Return.String()
+}
+
+// param returns the type of the i-th argument of a call, or nil if f
+// does not accept that many arguments.
+func (f *Function) param(i int) Type {
+	if i < len(f.Params) {
+		return f.Params[i]
+	}
+	return f.Rest
+}
+
+// Identical reports whether a and b are the same type.
+func Identical(a, b Type) bool {
+	switch a := a.(type) {
+	case *Basic:
+		return a == b
+	case *Array:
+		b, ok := b.(*Array)
+		return ok && Identical(a.Element, b.Element)
+	case *Hash:
+		b, ok := b.(*Hash)
+		return ok && Identical(a.Key, b.Key) && Identical(a.Value, b.Value)
+	case *Function:
+		b, ok := b.(*Function)
+		if !ok || len(a.Params) != len(b.Params) || a.Required != b.Required ||
+			(a.Rest == nil) != (b.Rest == nil) || !Identical(a.Return, b.Return) {
+			return false
+		}
+		for i := range a.Params {
+			if !Identical(a.Params[i], b.Params[i]) {
+				return false
+			}
+		}
+		return a.Rest == nil || Identical(a.Rest, b.Rest)
+	}
+	return false
+}
+
+// Assignable reports whether a value of type from can be used where a
+// value of type to is expected. Any is assignable in both directions and
+// int is assignable to float.
+func Assignable(from, to Type) bool {
+	if from == Any || to == Any {
+		return true
+	}
+
+	switch to := to.(type) {
+	case *Basic:
+		return from == to || from == Int && to == Float
+	case *Array:
+		from, ok := from.(*Array)
+		return ok && Assignable(from.Element, to.Element)
+	case *Hash:
+		from, ok := from.(*Hash)
+		return ok && Assignable(from.Key, to.Key) && Assignable(from.Value, to.Value)
+	case *Function:
+		from, ok := from.(*Function)
+		if !ok {
+			return false
+		}
+		// to で許される呼び出しは全て from でも受け付けなければならない
+		if from.Required > len(to.Params) || len(to.Params) > len(from.Params) && from.Rest == nil {
+			return false
+		}
+		for i, p := range to.Params {
+			if !Assignable(p, from.param(i)) {
+				return false
+			}
+		}
+		if to.Rest != nil && (from.Rest == nil || !Assignable(to.Rest, from.Rest)) {
+			return false
+		}
+		return Assignable(from.Return, to.Return)
+	}
+	return false
+}
+
+// join returns the type of a value that is either of type a or b. A nil
+// type stands for no value, as for a block that always returns.
+func join(a, b Type) Type {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a == Any || b == Any:
+		return Any
+	case Identical(a, b):
+		return a
+	case isNumber(a) && isNumber(b):
+		return Float
+	}
+
+	switch a := a.(type) {
+	case *Array:
+		if b, ok := b.(*Array); ok {
+			return &Array{Element: join(a.Element, b.Element)}
+		}
+	case *Hash:
+		if b, ok := b.(*Hash); ok {
+			return &Hash{Key: join(a.Key, b.Key), Value: join(a.Value, b.Value)}
+		}
+	}
+	return Any
+}
+
+func isNumber(t Type) bool {
+	return t == Int || t == Float
+}
+
+// isHashable reports whether values of type t can be hash keys.
+func isHashable(t Type) bool {
+	return t == Int || t == String || t == Bool || t == Any
+}
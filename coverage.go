@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Bo0km4n/dummy-monkey/coverage"
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+)
+
+const coverageUsage = "write a coverage report to `file`, as HTML if it ends in .html and in lcov format otherwise"
+
+// startCoverage makes the evaluator count coverage until the returned
+// function is called, which writes the report to file and the summary to
+// summary. A script calling exit() also writes them before exiting, and
+// errors doing so to stderr.
+func startCoverage(file string, summary, stderr io.Writer) (stop func() error) {
+	profile := coverage.New()
+	evaluator.Coverage = profile
+	exit := evaluator.Exit
+
+	written := false
+	stop = func() error {
+		evaluator.Coverage, evaluator.Exit = nil, exit
+		if written {
+			return nil
+		}
+		written = true
+		if err := profile.WriteSummary(summary); err != nil {
+			return err
+		}
+		return writeCoverage(profile, file)
+	}
+	evaluator.Exit = func(code int) {
+		if err := stop(); err != nil {
+			fmt.Fprintln(stderr, err)
+		}
+		exit(code)
+	}
+	return stop
+}
+
+func writeCoverage(profile *coverage.Profile, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(file) == ".html" {
+		err = profile.WriteHTML(f)
+	} else {
+		err = profile.WriteLcov(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+// Package coverage counts how many times the statements of Monkey
+// programs and the branches of their if expressions and switch statements
+// run, and writes reports that map the counts back to the source.
+//
+// The evaluator records into a Profile; the programs are added to it
+// before they are evaluated so that the statements that never run are
+// reported too.
+package coverage
+
+import (
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// A Profile holds the counts of the programs added to it.
+type Profile struct {
+	files      []*file
+	statements map[ast.Node]*counter
+	branches   map[ast.Node]*block
+}
+
+type file struct {
+	name       string
+	src        string
+	statements []*counter
+	blocks     []*block
+}
+
+// counter is a statement or a branch starting at line and column.
+type counter struct {
+	line, column int
+	hits         int
+}
+
+// block is an if expression or a switch statement. An if has two
+// branches, the consequence and the alternative (written or not); a
+// switch has one per case and a last one taken when no case matches.
+type block struct {
+	line     int
+	branches []*counter
+}
+
+// New returns an empty Profile.
+func New() *Profile {
+	return &Profile{
+		statements: map[ast.Node]*counter{},
+		branches:   map[ast.Node]*block{},
+	}
+}
+
+// Add registers the statements and branches of program, the contents of
+// the file name, with no hits. Adding a file again does nothing.
+func (p *Profile) Add(name string, src []byte, program *ast.Program) {
+	for _, f := range p.files {
+		if f.name == name {
+			return
+		}
+	}
+	f := &file{name: name, src: string(src)}
+	p.files = append(p.files, f)
+
+	ast.Inspect(program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfExpression:
+			els := n.Consequence.End
+			if n.Alternative != nil {
+				els = n.Alternative.Token
+			}
+			p.addBlock(f, n, n.Token, n.Consequence.Token, els)
+		case *ast.SwitchStatement:
+			starts := []token.Token{}
+			for _, c := range n.Case {
+				starts = append(starts, c.Token)
+			}
+			p.addBlock(f, n, n.Token, append(starts, n.End)...)
+		}
+		if tok, ok := statementToken(n); ok && tok.Line > 0 {
+			c := &counter{line: tok.Line, column: tok.Column}
+			p.statements[n] = c
+			f.statements = append(f.statements, c)
+		}
+		return n != nil
+	})
+}
+
+func (p *Profile) addBlock(f *file, n ast.Node, tok token.Token, starts ...token.Token) {
+	if tok.Line == 0 {
+		return
+	}
+	b := &block{line: tok.Line}
+	for _, start := range starts {
+		b.branches = append(b.branches, &counter{line: start.Line, column: start.Column})
+	}
+	p.branches[n] = b
+	f.blocks = append(f.blocks, b)
+}
+
+// statementToken returns the first token of a statement. Blocks and
+// cases are not counted as statements, cases being branches, and neither
+// is export: the let statement it exports is.
+func statementToken(n ast.Node) (token.Token, bool) {
+	switch n := n.(type) {
+	case *ast.LetStatement:
+		return n.Token, true
+	case *ast.ReturnStatement:
+		return n.Token, true
+	case *ast.ExpressionStatement:
+		return n.Token, true
+	case *ast.DoublePlusStatement:
+		return n.Token, true
+	case *ast.SwitchStatement:
+		return n.Token, true
+	case *ast.ImportStatement:
+		return n.Token, true
+	}
+	return token.Token{}, false
+}
+
+// Statement counts a run of the statement node. Nodes that were not added
+// are ignored.
+func (p *Profile) Statement(node ast.Node) {
+	if c, ok := p.statements[node]; ok {
+		c.hits++
+	}
+}
+
+// Branch counts that branch i of the if expression or switch statement
+// node was taken.
+func (p *Profile) Branch(node ast.Node, i int) {
+	if b, ok := p.branches[node]; ok && i < len(b.branches) {
+		b.branches[i].hits++
+	}
+}
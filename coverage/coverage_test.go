@@ -0,0 +1,101 @@
+package coverage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+const input = `let x = 1;
+if (x > 0) { puts("positive") }
+switch {
+case x == 1:
+	puts("one")
+case x == 2:
+	puts("two")
+}
+`
+
+// profile adds input to a new Profile and counts a run in which x is 1.
+func profile(t *testing.T) *Profile {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+
+	prof := New()
+	prof.Add("x.monkey", []byte(input), program)
+	ifExp := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	switchStmt := program.Statements[2].(*ast.SwitchStatement)
+	for _, stmt := range []ast.Node{program.Statements[0], program.Statements[1], ifExp.Consequence.Statements[0], switchStmt, switchStmt.Case[0].Statements[0]} {
+		prof.Statement(stmt)
+	}
+	prof.Branch(ifExp, 0)
+	prof.Branch(switchStmt, 0)
+	// 追加されていないノードは数えない
+	prof.Statement(ifExp.Condition)
+	prof.Branch(ifExp.Condition, 0)
+	return prof
+}
+
+func TestSummary(t *testing.T) {
+	var out bytes.Buffer
+	profile(t).WriteSummary(&out)
+
+	expected := "x.monkey  5/6 statements (83.3%)  2/5 branches (40.0%)\n" +
+		"total     5/6 statements (83.3%)  2/5 branches (40.0%)\n"
+	if out.String() != expected {
+		t.Errorf("wrong summary.\ngot:\n%s\nwant:\n%s", out.String(), expected)
+	}
+}
+
+func TestLcov(t *testing.T) {
+	var out bytes.Buffer
+	profile(t).WriteLcov(&out)
+
+	expected := `TN:
+SF:x.monkey
+DA:1,1
+DA:2,1
+DA:3,1
+DA:5,1
+DA:7,0
+LF:5
+LH:4
+BRDA:2,0,0,1
+BRDA:2,0,1,0
+BRDA:4,1,0,1
+BRDA:6,1,1,0
+BRDA:8,1,2,0
+BRF:5
+BRH:2
+end_of_record
+`
+	if out.String() != expected {
+		t.Errorf("wrong lcov report.\ngot:\n%s\nwant:\n%s", out.String(), expected)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var out bytes.Buffer
+	if err := profile(t).WriteHTML(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<span class="line covered"><span class="number">1</span><span class="hits">1</span>let x = 1;</span>`,
+		`<span class="line partial"><span class="number">2</span><span class="hits">1</span>if (x &gt; 0) { puts(&#34;positive&#34;) }</span>`,
+		`<span class="line uncovered"><span class="number">7</span><span class="hits">0</span>	puts(&#34;two&#34;)</span>`,
+		`<span class="line uncovered"><span class="number">8</span><span class="hits"></span>}</span>`,
+		`5/6 statements (83.3%), 2/5 branches (40.0%)`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("HTML report does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteSummary writes a line per file with the statements and branches
+// that ran, followed by the totals:
+//
+//	main.monkey  10/12 statements (83.3%)  3/4 branches (75.0%)
+//	total        10/12 statements (83.3%)  3/4 branches (75.0%)
+func (p *Profile) WriteSummary(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	var total stats
+	for _, f := range p.files {
+		s := f.stats()
+		fmt.Fprintf(tw, "%s\t%s\n", f.name, s)
+		total.add(s)
+	}
+	fmt.Fprintf(tw, "total\t%s\n", total)
+	return tw.Flush()
+}
+
+type stats struct {
+	statements, statementsRun int
+	branches, branchesTaken   int
+}
+
+func (s *stats) add(t stats) {
+	s.statements += t.statements
+	s.statementsRun += t.statementsRun
+	s.branches += t.branches
+	s.branchesTaken += t.branchesTaken
+}
+
+func (s stats) String() string {
+	return fmt.Sprintf("%d/%d statements (%s)\t%d/%d branches (%s)",
+		s.statementsRun, s.statements, percent(s.statementsRun, s.statements),
+		s.branchesTaken, s.branches, percent(s.branchesTaken, s.branches))
+}
+
+// percent formats n of total; nothing to cover counts as fully covered.
+func percent(n, total int) string {
+	if total == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
+
+func (f *file) stats() stats {
+	var s stats
+	for _, c := range f.statements {
+		s.statements++
+		if c.hits > 0 {
+			s.statementsRun++
+		}
+	}
+	for _, b := range f.blocks {
+		for _, c := range b.branches {
+			s.branches++
+			if c.hits > 0 {
+				s.branchesTaken++
+			}
+		}
+	}
+	return s
+}
+
+// line is what a source line holds.
+type line struct {
+	statements bool // 文が始まる行か
+	hits       int  // 行で始まる文が実行された回数の最大
+	run        int  // 実行された文と選ばれた分岐の数
+	missed     int  // 実行されなかった文と選ばれなかった分岐の数
+}
+
+func (f *file) lines() map[int]*line {
+	lines := map[int]*line{}
+	get := func(n int) *line {
+		if lines[n] == nil {
+			lines[n] = &line{}
+		}
+		return lines[n]
+	}
+	count := func(l *line, c *counter) {
+		if c.hits > 0 {
+			l.run++
+		} else {
+			l.missed++
+		}
+	}
+
+	for _, c := range f.statements {
+		l := get(c.line)
+		l.statements = true
+		if c.hits > l.hits {
+			l.hits = c.hits
+		}
+		count(l, c)
+	}
+	for _, b := range f.blocks {
+		for _, c := range b.branches {
+			count(get(c.line), c)
+		}
+	}
+	return lines
+}
+
+// class is the CSS class of a source line in the HTML report.
+func (l *line) class() string {
+	switch {
+	case l == nil:
+		return ""
+	case l.missed == 0:
+		return "covered"
+	case l.run == 0:
+		return "uncovered"
+	default:
+		return "partial"
+	}
+}
+
+// WriteLcov writes the profile in the lcov tracefile format read by
+// genhtml and most coverage services: a record per file with DA lines for
+// the lines where statements start and BRDA lines for the branches.
+func (p *Profile) WriteLcov(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range p.files {
+		fmt.Fprintf(bw, "TN:\nSF:%s\n", f.name)
+
+		lines := f.lines()
+		numbers := []int{}
+		for n, l := range lines {
+			if l.statements {
+				numbers = append(numbers, n)
+			}
+		}
+		sort.Ints(numbers)
+		hit := 0
+		for _, n := range numbers {
+			fmt.Fprintf(bw, "DA:%d,%d\n", n, lines[n].hits)
+			if lines[n].hits > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\n", len(numbers), hit)
+
+		s := f.stats()
+		for i, b := range f.blocks {
+			reached := false
+			for _, c := range b.branches {
+				reached = reached || c.hits > 0
+			}
+			for j, c := range b.branches {
+				// 分岐に達しなかった場合は - を書く
+				taken := "-"
+				if reached {
+					taken = strconv.Itoa(c.hits)
+				}
+				fmt.Fprintf(bw, "BRDA:%d,%d,%d,%s\n", c.line, i, j, taken)
+			}
+		}
+		fmt.Fprintf(bw, "BRF:%d\nBRH:%d\nend_of_record\n", s.branches, s.branchesTaken)
+	}
+	return bw.Flush()
+}
+
+var htmlReport = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage</title>
+<style>
+body { font-family: sans-serif; }
+pre { font-size: 13px; line-height: 1.4; }
+.line { display: block; }
+.number, .hits { display: inline-block; width: 4em; margin-right: 1em; text-align: right; color: #999; }
+.covered { background: #d8f5d8; }
+.uncovered { background: #f8d8d8; }
+.partial { background: #f8f0c8; }
+</style>
+</head>
+<body>
+<h1>Coverage</h1>
+<table>
+{{- range .}}
+<tr><td><a href="#{{.Name}}">{{.Name}}</a></td><td>{{.Stats}}</td></tr>
+{{- end}}
+</table>
+{{- range .}}
+<h2 id="{{.Name}}">{{.Name}}</h2>
+<pre>
+{{- range .Lines}}<span class="line {{.Class}}"><span class="number">{{.Number}}</span><span class="hits">{{.Hits}}</span>{{.Text}}</span>{{end -}}
+</pre>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlFile struct {
+	Name  string
+	Stats string
+	Lines []htmlLine
+}
+
+type htmlLine struct {
+	Number int
+	Class  string
+	Hits   string
+	Text   string
+}
+
+// WriteHTML writes a page showing the source of every file with the lines
+// that ran, did not run or ran only in part (some of their statements or
+// branches did not) highlighted, and the hits of the statements starting
+// on each line.
+func (p *Profile) WriteHTML(w io.Writer) error {
+	files := []htmlFile{}
+	for _, f := range p.files {
+		hf := htmlFile{Name: f.name, Stats: strings.Replace(f.stats().String(), "\t", ", ", -1)}
+		lines := f.lines()
+		for i, text := range strings.Split(strings.TrimSuffix(f.src, "\n"), "\n") {
+			l := lines[i+1]
+			hl := htmlLine{Number: i + 1, Class: l.class(), Text: text}
+			if l != nil && l.statements {
+				hl.Hits = strconv.Itoa(l.hits)
+			}
+			hf.Lines = append(hf.Lines, hl)
+		}
+		files = append(files, hf)
+	}
+	return htmlReport.Execute(w, files)
+}
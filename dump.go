@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// dumpTokens writes the tokens of src, one per line as
+// `line:column TYPE "literal"`, or as a JSON array.
+func dumpTokens(src string, asJSON bool, stdout io.Writer) {
+	type jsonToken struct {
+		Type    token.TokenType `json:"type"`
+		Literal string          `json:"literal"`
+		Line    int             `json:"line"`
+		Column  int             `json:"column"`
+	}
+
+	l := lexer.New(src)
+	tokens := []jsonToken{}
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, jsonToken{tok.Type, tok.Literal, tok.Line, tok.Column})
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+
+	if asJSON {
+		out, _ := json.MarshalIndent(tokens, "", "  ")
+		fmt.Fprintln(stdout, string(out))
+		return
+	}
+	for _, tok := range tokens {
+		fmt.Fprintf(stdout, "%d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Literal)
+	}
+}
+
+// dumpAST writes the AST of src as an indented tree or as JSON. Like
+// `monkey run`, it returns 2 if src does not parse.
+func dumpAST(src string, asJSON bool, stdout, stderr io.Writer) int {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			fmt.Fprintln(stderr, msg)
+		}
+		return 2
+	}
+
+	if asJSON {
+		out, err := ast.MarshalJSON(program)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		var indented bytes.Buffer
+		json.Indent(&indented, out, "", "  ")
+		fmt.Fprintln(stdout, indented.String())
+		return 0
+	}
+	ast.Fprint(stdout, program)
+	return 0
+}
@@ -0,0 +1,144 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+// assert_error は関数を呼び出すので, builtins の初期化の循環を避けて init で登録する
+func init() {
+	for name, fn := range map[string]object.BuiltinFunction{
+		"assert":       _builtinAssert,
+		"assert_eq":    _builtinAssertEq,
+		"assert_error": _builtinAssertError,
+	} {
+		builtins[name] = &object.Builtin{Name: name, Fn: fn}
+	}
+}
+
+// assert(cond, message?) fails unless cond is truthy.
+func _builtinAssert(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments to `assert`. got=%d, want=1 or 2", len(args))
+	}
+	if isTruthy(args[0]) {
+		return NULL
+	}
+	return assertionError("assert", args[1:], "got %s", args[0].Inspect())
+}
+
+// assert_eq(actual, expected, message?) fails unless the values are equal,
+// comparing arrays and hashes element by element.
+func _builtinAssertEq(args ...object.Object) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments to `assert_eq`. got=%d, want=2 or 3", len(args))
+	}
+	actual, expected := args[0], args[1]
+	if Equal(actual, expected) {
+		return NULL
+	}
+
+	want, got := expected.Inspect(), actual.Inspect()
+	// 1 と "1" のように見た目が同じなら型も表示する
+	if want == got {
+		want += " (" + string(expected.Type()) + ")"
+		got += " (" + string(actual.Type()) + ")"
+	}
+	return assertionError("assert_eq", args[2:], "expected %s, got %s", want, got)
+}
+
+// assert_error(fn, substring?) calls fn without arguments and fails unless
+// it returns an error whose message contains substring.
+func _builtinAssertError(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments to `assert_error`. got=%d, want=1 or 2", len(args))
+	}
+	if args[0].Type() != object.FUNCTION_OBJ && args[0].Type() != object.BUILTIN_OBJ {
+		return newError("argument 1 to `assert_error` must be FUNCTION, got %s", args[0].Type())
+	}
+	substring := ""
+	if len(args) == 2 {
+		s, ok := args[1].(*object.String)
+		if !ok {
+			return newError("argument 2 to `assert_error` must be STRING, got %s", args[1].Type())
+		}
+		substring = s.Value
+	}
+
+	result := applyFunction(args[0], nil, nil)
+	err, ok := result.(*object.Error)
+	switch {
+	case !ok && substring == "":
+		return assertionError("assert_error", nil, "expected an error, got %s", result.Inspect())
+	case !ok:
+		return assertionError("assert_error", nil, "expected an error containing %q, got %s", substring, result.Inspect())
+	case !strings.Contains(err.Message, substring):
+		return assertionError("assert_error", nil, "expected an error containing %q, got %s", substring, err.Inspect())
+	}
+	return NULL
+}
+
+// assertionError returns the failure of the assertion name, prefixed with
+// the optional message passed to it.
+func assertionError(name string, message []object.Object, format string, a ...interface{}) *object.Error {
+	err := newError("assertion failed: "+format, a...)
+	if len(message) == 1 {
+		msg, ok := message[0].(*object.String)
+		if !ok {
+			return newError("message of `%s` must be STRING, got %s", name, message[0].Type())
+		}
+		err.Message = "assertion failed: " + msg.Value + ": " + strings.TrimPrefix(err.Message, "assertion failed: ")
+	}
+	return err
+}
+
+// Equal reports whether a and b are the same value. Arrays and hashes are
+// equal if their elements are; functions only if they are the same one.
+func Equal(a, b object.Object) bool {
+	switch a := a.(type) {
+	case *object.Integer:
+		b, ok := b.(*object.Integer)
+		return ok && a.Value == b.Value
+	case *object.Float:
+		b, ok := b.(*object.Float)
+		return ok && a.Value == b.Value
+	case *object.String:
+		b, ok := b.(*object.String)
+		return ok && a.Value == b.Value
+	case *object.Array:
+		b, ok := b.(*object.Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !Equal(a.Elements[i], b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		b, ok := b.(*object.Hash)
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+		for key, pair := range a.Pairs {
+			other, ok := b.Pairs[key]
+			if !ok || !Equal(pair.Value, other.Value) {
+				return false
+			}
+		}
+		return true
+	case *object.Error:
+		b, ok := b.(*object.Error)
+		return ok && a.Message == b.Message
+	}
+	// 真偽値と null は共有されたオブジェクト
+	return a == b
+}
+
+// Apply calls the function or builtin fn with args, as a call expression
+// does, and returns the result.
+func Apply(fn object.Object, args ...object.Object) object.Object {
+	return applyFunction(fn, args, nil)
+}
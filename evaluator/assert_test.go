@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func TestAssertBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // 空なら成功
+	}{
+		{`assert(true)`, ""},
+		{`assert(1)`, ""},
+		{`assert(false)`, "assertion failed: got false"},
+		{`assert(1 > 2, "order")`, "assertion failed: order: got false"},
+		{`assert()`, "wrong number of arguments to `assert`. got=0, want=1 or 2"},
+		{`assert(false, 1)`, "message of `assert` must be STRING, got INTEGER"},
+		{`assert_eq(1 + 1, 2)`, ""},
+		{`assert_eq([1, [2, "a"]], [1, [2, "a"]])`, ""},
+		{`assert_eq({"a": 1, "b": [true]}, {"b": [true], "a": 1})`, ""},
+		{`assert_eq(1, 2)`, "assertion failed: expected 2, got 1"},
+		{`assert_eq([1, 2], [1, 3], "lists")`, "assertion failed: lists: expected [1, 3], got [1, 2]"},
+		{`assert_eq("1", 1)`, "assertion failed: expected 1 (INTEGER), got 1 (STRING)"},
+		{`assert_eq(1, 1.0)`, "assertion failed: expected 1.0, got 1"},
+		{`assert_eq({"a": 1}, {"a": 2})`, "assertion failed: expected {a: 2}, got {a: 1}"},
+		{`assert_error(fn() { 1 + "a" })`, ""},
+		{`assert_error(fn() { 1 + "a" }, "mismatch")`, ""},
+		{`assert_error(fn() { 1 })`, "assertion failed: expected an error, got 1"},
+		{`assert_error(fn() { 1 }, "boom")`, "assertion failed: expected an error containing \"boom\", got 1"},
+		{`assert_error(fn() { -true }, "boom")`, "assertion failed: expected an error containing \"boom\", got ERROR: unknown operator: -BOOLEAN"},
+		{`assert_error(1)`, "argument 1 to `assert_error` must be FUNCTION, got INTEGER"},
+		{`let f = fn() { assert_eq(1, 2); puts("not reached") }; f()`, "assertion failed: expected 2, got 1"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		if tt.expected == "" {
+			if result != NULL {
+				t.Errorf("%s: expected success, got %s", tt.input, result.Inspect())
+			}
+			continue
+		}
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error, got %T (%+v)", tt.input, result, result)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong message. expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	fn := testEval(`fn() { 1 }`)
+	tests := []struct {
+		a, b     object.Object
+		expected bool
+	}{
+		{testEval(`1`), testEval(`1`), true},
+		{testEval(`"a"`), testEval(`"a"`), true},
+		{NULL, NULL, true},
+		{TRUE, testEval(`1 < 2`), true},
+		{testEval(`[]`), testEval(`{}`), false},
+		{testEval(`[1, 2]`), testEval(`[1]`), false},
+		{testEval(`{1: 2}`), testEval(`{2: 2}`), false},
+		{fn, fn, true},
+		{fn, testEval(`fn() { 1 }`), false},
+	}
+
+	for i, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.expected {
+			t.Errorf("tests[%d]: Equal(%s, %s) = %t, want %t", i, tt.a.Inspect(), tt.b.Inspect(), got, tt.expected)
+		}
+	}
+}
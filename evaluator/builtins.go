@@ -1,11 +1,17 @@
 package evaluator
 
 import (
-	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/Bo0km4n/dummy-monkey/object"
 )
 
+// Stdout is where puts and printf write their output.
+var Stdout io.Writer = os.Stdout
+
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
 		Fn: _builtinLen,
@@ -13,6 +19,9 @@ var builtins = map[string]*object.Builtin{
 	"puts": &object.Builtin{
 		Fn: _builtinPuts,
 	},
+	"exit": &object.Builtin{
+		Fn: _builtinExit,
+	},
 	"first": &object.Builtin{
 		Fn: _builtinFirst,
 	},
@@ -25,6 +34,37 @@ var builtins = map[string]*object.Builtin{
 	"push": &object.Builtin{
 		Fn: _builtinPush,
 	},
+	"json_parse": &object.Builtin{
+		Fn: _builtinJSONParse,
+	},
+	"json_stringify": &object.Builtin{
+		Fn: _builtinJSONStringify,
+	},
+	"regex": &object.Builtin{
+		Fn: _builtinRegex,
+	},
+	"sprintf": &object.Builtin{
+		Fn: _builtinSprintf,
+	},
+	"printf": &object.Builtin{
+		Fn: _builtinPrintf,
+	},
+}
+
+func init() {
+	for name, builtin := range builtins {
+		builtin.Name = name
+	}
+}
+
+// BuiltinNames returns the names of all builtin functions.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func _builtinLen(args ...object.Object) object.Object {
@@ -42,23 +82,44 @@ func _builtinLen(args ...object.Object) object.Object {
 }
 
 func _builtinPuts(args ...object.Object) object.Object {
-	in := []interface{}{}
+	in := []string{}
 
 	for _, v := range args {
 		switch v := v.(type) {
 		case *object.String:
 			in = append(in, v.Value)
-		case *object.Integer:
-			in = append(in, v.Value)
-		case *object.Boolean:
-			in = append(in, v.Value)
 		case *object.Error:
 			in = append(in, v.Message)
 		default:
-			return newError("argument to `puts` not supported, got %s", args[0].Type())
+			in = append(in, v.Inspect())
+		}
+	}
+	io.WriteString(Stdout, strings.Join(in, " ")+"\n")
+	return NULL
+}
+
+// flusher is implemented by buffered writers such as *bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+func _builtinExit(args ...object.Object) object.Object {
+	code := int64(0)
+	switch len(args) {
+	case 0:
+	case 1:
+		if err := checkArgs("exit", args, object.INTEGER_OBJ); err != nil {
+			return err
 		}
+		code = args[0].(*object.Integer).Value
+	default:
+		return newError("wrong number of arguments to `exit`. got=%d, want=0 or 1", len(args))
+	}
+	// os.Exit は defer を実行しないので, ここで出力を書き出す
+	if f, ok := Stdout.(flusher); ok {
+		f.Flush()
 	}
-	fmt.Println(in...)
+	Exit(int(code))
 	return NULL
 }
 
@@ -0,0 +1,43 @@
+package evaluator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/coverage"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func TestCoverage(t *testing.T) {
+	defer func() { Coverage = nil }()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1; if (x > 1) { x } else { 0 }; x",
+			"4/5 statements (80.0%)  1/2 branches (50.0%)"},
+		{"let f = fn(n) { if (n) { 1 } }; f(false); f(false)",
+			"4/5 statements (80.0%)  1/2 branches (50.0%)"},
+		{"switch { case false: 1 case true: 2 }",
+			"2/3 statements (66.7%)  1/3 branches (33.3%)"},
+		{"switch 1 { case false: 1 }",
+			"1/2 statements (50.0%)  1/2 branches (50.0%)"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		Coverage = coverage.New()
+		Coverage.Add("t", []byte(tt.input), program)
+		Eval(program, object.NewEnvironment())
+
+		var out bytes.Buffer
+		Coverage.WriteSummary(&out)
+		expected := "t      " + tt.expected + "\ntotal  " + tt.expected + "\n"
+		if out.String() != expected {
+			t.Errorf("%q: wrong coverage.\ngot:\n%s\nwant:\n%s", tt.input, out.String(), expected)
+		}
+	}
+}
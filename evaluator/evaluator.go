@@ -2,8 +2,11 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/coverage"
 	"github.com/Bo0km4n/dummy-monkey/object"
 )
 
@@ -13,7 +16,33 @@ var (
 	NULL  = &object.NULL{}
 )
 
+// MaxSteps, when positive, bounds the number of nodes Eval evaluates
+// after ResetSteps. Beyond it Eval returns an error, which stops runaway
+// programs such as the ones generated by the fuzz tests.
+var MaxSteps int
+
+var steps int
+
+// ResetSteps restarts counting the nodes evaluated for MaxSteps.
+func ResetSteps() {
+	steps = 0
+}
+
+// Coverage, when not nil, counts the statements and the if and switch
+// branches that Eval runs in the programs added to it.
+var Coverage *coverage.Profile
+
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	if MaxSteps > 0 {
+		if steps >= MaxSteps {
+			return newError("step limit of %d exceeded", MaxSteps)
+		}
+		steps++
+	}
+	if Coverage != nil {
+		Coverage.Statement(node)
+	}
+
 	switch node := node.(type) {
 
 	// 文
@@ -24,28 +53,34 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.ReturnStatement:
-		val := Eval(node.ReturnValue, env)
+		val := evalTail(node.ReturnValue, env)
 		if isError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
 	case *ast.DoublePlusStatement:
-		ident, ok := env.Get(node.Name.Value)
+		ident, ok := lookupIdentifier(node.Name, env)
 		if !ok {
 			return newError("not found identifier: %q", node.Name.Value)
 		}
 		val := evalDoublePlusStatement(ident)
-		env.Set(node.Name.Value, val)
+		bindIdentifier(node.Name, env, val)
 		return val
 	case *ast.SwitchStatement:
 		if node.Expression != nil {
 			Eval(node.Expression, env)
 		}
 		return evalSwitchStatement(node, env)
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+	case *ast.ExportStatement:
+		return Eval(node.Statement, env)
 
 	// 式
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.StringLiteral:
@@ -75,28 +110,35 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		// let f = fn() {...} の関数は f という名前を覚えておく
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+				fn.Name = node.Name.Value
+			}
+		}
+		bindIdentifier(node.Name, env, val)
 		return val
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{
+		fn := &object.Function{
 			Parameters: params,
+			Defaults:   node.Defaults,
+			Rest:       node.Rest,
 			Env:        env,
 			Body:       body,
+			Slots:      node.Slots,
 		}
-	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
-			return function
-		}
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		// fn name(...) {...} は宣言した環境に name を束縛する
+		if node.Name != nil {
+			fn.Name = node.Name.Value
+			bindIdentifier(node.Name, env, fn)
 		}
-		return applyFunction(function, args)
+		return fn
+	case *ast.CallExpression:
+		return evalCallExpression(node, env, false)
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -115,6 +157,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalIndexExpression(left, index)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
+	case *ast.DotExpression:
+		return evalDotExpression(node, env)
+	case *ast.SpreadExpression:
+		return newError("spread operator is only allowed in calls and array literals: %s", node.String())
 	}
 	return newError("not implemented value: %T => %q", node, node.String())
 }
@@ -160,6 +206,9 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	if f, ok := right.(*object.Float); ok {
+		return &object.Float{Value: -f.Value}
+	}
 	if right.Type() != object.INTEGER_OBJ {
 		return newError("unknown operator: -%s", right.Type())
 	}
@@ -171,6 +220,8 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
@@ -198,6 +249,9 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %d / 0", leftVal)
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
@@ -208,12 +262,58 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "!=":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 	case "%":
+		if rightVal == 0 {
+			return newError("division by zero: %d %% 0", leftVal)
+		}
 		return &object.Integer{Value: leftVal % rightVal}
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat converts an INTEGER or FLOAT object to a float64.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
@@ -231,7 +331,7 @@ func evalBinaryOperand(operator string, left, right object.Object) object.Object
 	rightVal := right.(*object.Boolean).Value
 	switch operator {
 	case "&&":
-		return &object.Boolean{Value: leftVal && rightVal}
+		return nativeBoolToBooleanObject(leftVal && rightVal)
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
@@ -242,6 +342,7 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	if isError(condition) {
 		return condition
 	}
+	coverBranch(ie, isTruthy(condition))
 	if isTruthy(condition) {
 		return Eval(ie.Consequence, env)
 	} else if ie.Alternative != nil {
@@ -251,6 +352,18 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+// coverBranch counts the branch of the if expression ie that runs.
+func coverBranch(ie *ast.IfExpression, truthy bool) {
+	if Coverage == nil {
+		return
+	}
+	if truthy {
+		Coverage.Branch(ie, 0)
+	} else {
+		Coverage.Branch(ie, 1)
+	}
+}
+
 func isTruthy(condition object.Object) bool {
 	switch condition {
 	case NULL:
@@ -270,7 +383,7 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 		result = Eval(statement, env)
 		switch result := result.(type) {
 		case *object.ReturnValue:
-			return result.Value
+			return forceTailCall(result.Value)
 		case *object.Error:
 			return result
 		}
@@ -309,7 +422,7 @@ func isError(obj object.Object) bool {
 }
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	if val, ok := env.Get(node.Value); ok {
+	if val, ok := lookupIdentifier(node, env); ok {
 		return val
 	}
 
@@ -319,10 +432,46 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	return newError("identifier not found: " + node.Value)
 }
 
+// lookupIdentifier reads a variable from the slot the resolver assigned to
+// it, or by name for unresolved identifiers. A resolved variable whose
+// declaration has not run yet falls back to a lookup by name.
+func lookupIdentifier(node *ast.Identifier, env *object.Environment) (object.Object, bool) {
+	if node.Resolved {
+		if val, ok := env.GetAt(node.Depth, node.Slot); ok {
+			return val, true
+		}
+	}
+	return env.Get(node.Value)
+}
+
+// bindIdentifier sets the variable node in the slot the resolver assigned
+// to it, or by name in env for unresolved identifiers.
+func bindIdentifier(node *ast.Identifier, env *object.Environment, val object.Object) {
+	if node.Resolved {
+		env.SetAt(node.Depth, node.Slot, val)
+		return
+	}
+	env.Set(node.Value, val)
+}
+
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
 	for _, e := range exps {
+		// ...arr は配列の要素に展開する
+		if spread, ok := e.(*ast.SpreadExpression); ok {
+			evaluated := Eval(spread.Value, env)
+			if isError(evaluated) {
+				return []object.Object{evaluated}
+			}
+			arr, ok := evaluated.(*object.Array)
+			if !ok {
+				return []object.Object{newError("cannot spread %s, want ARRAY", evaluated.Type())}
+			}
+			result = append(result, arr.Elements...)
+			continue
+		}
+
 		evaluated := Eval(e, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
@@ -332,27 +481,133 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
-	switch fn := fn.(type) {
-	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
-	case *object.Builtin:
-		return fn.Fn(args...)
-	default:
-		return newError("not a function: %s", fn.Type())
+func evalCallExpression(node *ast.CallExpression, env *object.Environment, tail bool) object.Object {
+	function := Eval(node.Function, env)
+	if isError(function) {
+		return function
+	}
+	args := evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	// 末尾位置の関数呼び出しは呼び出し元の applyFunction に実行させる
+	if fn, ok := function.(*object.Function); ok && tail {
+		return &tailCall{fn: fn, args: args, node: node}
 	}
+
+	result := applyFunction(function, args, node)
+	if err, ok := result.(*object.Error); ok {
+		err.Stack = append(err.Stack, callFrame(function, node))
+	}
+	return result
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+func callFrame(fn object.Object, node *ast.CallExpression) object.Frame {
+	return object.Frame{
+		Function: functionName(fn),
+		Line:     node.Token.Line,
+		Column:   node.Token.Column,
+	}
+}
+
+// applyFunction calls fn with args. node is the call expression, nil for
+// calls made by builtins and embedders.
+func applyFunction(fn object.Object, args []object.Object, node *ast.CallExpression) object.Object {
+	// 末尾呼び出しの記録. エラー時のスタックに積む
+	tails := &tailFrames{}
+
+	for {
+		switch f := fn.(type) {
+		case *object.Function:
+			extendedEnv, err := extendFunctionEnv(f, args, node)
+			if err != nil {
+				return tails.attach(err)
+			}
+			evaluated := unwrapReturnValue(evalTailBlock(f.Body, extendedEnv))
+
+			// 末尾呼び出しは Go のスタックを積まずにループで実行する
+			if call, ok := evaluated.(*tailCall); ok {
+				tails.push(callFrame(call.fn, call.node))
+				fn, args, node = call.fn, call.args, call.node
+				continue
+			}
+			return tails.attach(evaluated)
+		case *object.Builtin:
+			return f.Fn(args...)
+		default:
+			return newError("not a function: %s", fn.Type())
+		}
+	}
+}
+
+func extendFunctionEnv(fn *object.Function, args []object.Object, node *ast.CallExpression) (*object.Environment, *object.Error) {
+	env := object.NewFrame(fn.Env, fn.Slots)
+
+	if len(args) > len(fn.Parameters) && fn.Rest == nil {
+		return nil, newError("too many arguments in call to %s%s. got=%d, want=%d",
+			describeFunction(fn), callPosition(node), len(args), len(fn.Parameters))
+	}
 
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		if paramIdx < len(args) {
+			bindIdentifier(param, env, args[paramIdx])
+			continue
+		}
+
+		// デフォルト値は関数の環境で評価するので前の引数を参照できる
+		if paramIdx >= len(fn.Defaults) || fn.Defaults[paramIdx] == nil {
+			return nil, newError("not enough arguments in call to %s%s: missing argument %d (%s)",
+				describeFunction(fn), callPosition(node), paramIdx+1, param.Value)
+		}
+		val := Eval(fn.Defaults[paramIdx], env)
+		if err, ok := val.(*object.Error); ok {
+			return nil, err
+		}
+		bindIdentifier(param, env, val)
+	}
+
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		bindIdentifier(fn.Rest, env, &object.Array{Elements: rest})
+	}
+
+	return env, nil
+}
+
+// describeFunction names fn in error messages, falling back to its
+// signature for anonymous functions.
+func describeFunction(fn *object.Function) string {
+	if fn.Name != "" {
+		return fn.Name
+	}
+	return "fn(" + strings.Join(ast.ParameterStrings(fn.Parameters, fn.Defaults, fn.Rest), ", ") + ")"
+}
+
+// callPosition locates a call in error messages.
+func callPosition(node *ast.CallExpression) string {
+	if node == nil || node.Token.Line == 0 {
+		return ""
 	}
+	return fmt.Sprintf(" at line %d, column %d", node.Token.Line, node.Token.Column)
+}
 
-	return env
+// functionName names a called object in stack traces.
+func functionName(fn object.Object) string {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if fn.Name != "" {
+			return fn.Name
+		}
+	case *object.Builtin:
+		if fn.Name != "" {
+			return fn.Name
+		}
+	}
+	return "<anonymous>"
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -363,7 +618,7 @@ func unwrapReturnValue(obj object.Object) object.Object {
 }
 
 func evalForExpression(node *ast.ForExpression, env *object.Environment) object.Object {
-	forEnv := object.NewEnclosedEnvironment(env)
+	forEnv := object.NewFrame(env, node.Slots)
 	initEvalResult := Eval(node.InitStatement, forEnv)
 	var result object.Object
 	if isError(initEvalResult) {
@@ -380,6 +635,9 @@ func evalForExpression(node *ast.ForExpression, env *object.Environment) object.
 			return result
 		}
 		result = evalBlockStatement(node.Consequence, forEnv)
+		if rt := result; rt != nil && (rt.Type() == object.RETURN_VALUE_OBJ || rt.Type() == object.ERROR_OBJ) {
+			return result
+		}
 		loopEvalResult := Eval(node.LoopStatement, forEnv)
 		if isError(loopEvalResult) {
 			return loopEvalResult
@@ -410,13 +668,16 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 }
 
 func evalSwitchStatement(node *ast.SwitchStatement, env *object.Environment) object.Object {
-	for _, c := range node.Case {
+	for i, c := range node.Case {
 		conditionValue := Eval(c.Condition, env)
 		condition, ok := conditionValue.(*object.Boolean)
 		if !ok {
 			return newError("case condtion value is not boolean. got=%T", conditionValue)
 		}
 		if condition.Value {
+			if Coverage != nil {
+				Coverage.Branch(node, i)
+			}
 			var result object.Object
 			for _, s := range c.Statements {
 				result = Eval(s, env)
@@ -424,6 +685,10 @@ func evalSwitchStatement(node *ast.SwitchStatement, env *object.Environment) obj
 			return result
 		}
 	}
+	// どの case にも当たらなかった
+	if Coverage != nil {
+		Coverage.Branch(node, len(node.Case))
+	}
 	return nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/Bo0km4n/dummy-monkey/lexer"
 	"github.com/Bo0km4n/dummy-monkey/object"
 	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/resolver"
 )
 
 func TestEvalIntegerExpression(t *testing.T) {
@@ -193,6 +194,14 @@ func TestErrorHandling(t *testing.T) {
 			"5 + true",
 			"type mismatch: INTEGER + BOOLEAN",
 		},
+		{
+			"let zero = 0; 10 / zero",
+			"division by zero: 10 / 0",
+		},
+		{
+			"7 % (1 - 1)",
+			"division by zero: 7 % 0",
+		},
 		{
 			"5 + true; 5;",
 			"type mismatch: INTEGER + BOOLEAN",
@@ -423,3 +432,285 @@ func TestBinaryOperand(t *testing.T) {
 		t.Errorf("Result was not false. got=%v", result)
 	}
 }
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1.5", 1.5},
+		{"-2.25", -2.25},
+		{"1.5 + 1", 2.5},
+		{"3 * 0.5", 1.5},
+		{"1 / 4.0", 0.25},
+		{"5.5 % 2", 1.5},
+		{"0.5 < 1", true},
+		{"2.0 == 2", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float, got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%v, want=%v", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func TestFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let add = fn(a, b = 10) { a + b }; add(1)", 11},
+		{"let add = fn(a, b = 10) { a + b }; add(1, 2)", 3},
+		{"let f = fn(a, b = a * 2) { b }; f(4)", 8},
+		{"let count = fn(first, ...rest) { len(rest) }; count(1, 2, 3)", 2},
+		{"let count = fn(first, ...rest) { len(rest) }; count(1)", 0},
+		{"let sum = fn(...xs) { if (len(xs) == 0) { 0 } else { first(xs) + sum(...rest(xs)) } }; sum(1, 2, 3, 4)", 10},
+		{"let add = fn(a, b) { a + b }; let args = [1, 2]; add(...args)", 3},
+		{"let add = fn(a, b, c) { a + b + c }; add(1, ...[2, 3])", 6},
+		{"len([0, ...[1, 2], 3])", 4},
+		{"len(...[[1, 2]])", 2},
+		{"let add = fn(a, b) { a + b }; add(1)", "not enough arguments in call to add at line 1, column 34: missing argument 2 (b)"},
+		{"let add = fn(a, b) { a + b }; add(1, 2, 3)", "too many arguments in call to add at line 1, column 34. got=3, want=2"},
+		{"let f = fn(a, b = 1) { a }; f()", "not enough arguments in call to f at line 1, column 30: missing argument 1 (a)"},
+		{"let f = fn(a) { a };\nlet g = fn() { f() };\ng()", "not enough arguments in call to f at line 2, column 17: missing argument 1 (a)"},
+		{"let f = fn(a = x) { a }; f()", "identifier not found: x"},
+		{"len(...1)", "cannot spread INTEGER, want ARRAY"},
+		{"...[1]", "spread operator is only allowed in calls and array literals: ...[1]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestNamedFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"fn add(a, b) { a + b }; add(1, 2)", 3},
+		{"fn fact(n) { if (n == 0) { 1 } else { n * fact(n - 1) } }; fact(5)", 120},
+		{"let f = fn g(x) { x }; g(7)", 7},
+		{"let double = fn(x) { x * 2 }; double", "double"},
+		{"fn triple(x) { x * 3 }", "triple"},
+		{"let f = fn g(x) { x }; f", "g"},
+		{"let h = fn(x) { x }; let k = h; k", "h"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			fn, ok := evaluated.(*object.Function)
+			if !ok {
+				t.Errorf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if fn.Name != expected {
+				t.Errorf("function has wrong name. expected=%q, got=%q", expected, fn.Name)
+			}
+		}
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	input := `let inner = fn(x) {
+	x + "one"
+};
+fn outer(x) {
+	inner(x)
+}
+let run = fn() { outer(1) };
+run();`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := []object.Frame{
+		{Function: "inner", Line: 5, Column: 7},
+		{Function: "outer", Line: 7, Column: 23},
+		{Function: "run", Line: 8, Column: 4},
+	}
+	if len(errObj.Stack) != len(expected) {
+		t.Fatalf("wrong stack depth. expected=%d, got=%d (%+v)", len(expected), len(errObj.Stack), errObj.Stack)
+	}
+	for i, frame := range expected {
+		if errObj.Stack[i] != frame {
+			t.Errorf("stack[%d] wrong. expected=%+v, got=%+v", i, frame, errObj.Stack[i])
+		}
+	}
+
+	builtinErr, ok := testEval(`let f = fn() { len(1) }; f()`).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned for builtin")
+	}
+	if builtinErr.Stack[0].Function != "len" || builtinErr.Stack[1].Function != "f" {
+		t.Errorf("wrong stack for builtin error. got=%+v", builtinErr.Stack)
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } }; count(1000000)", 0},
+		{"let sum = fn(n, acc) { if (n == 0) { return acc; } return sum(n - 1, acc + n); }; sum(1000000, 0)", 500000500000},
+		{"fn even(n) { if (n == 0) { true } else { odd(n - 1) } } fn odd(n) { if (n == 0) { false } else { even(n - 1) } } if (even(100001)) { 1 } else { 0 }", 0},
+		{"let f = fn(n) { n * 2 }; return f(21);", 42},
+		{"let g = fn(n) { for (let i = 0; i < 10; ++i) { if (i == n) { return i * 10; } } }; g(3)", 30},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMaxSteps(t *testing.T) {
+	defer func() { MaxSteps = 0 }()
+	MaxSteps = 1000
+
+	inputs := []string{
+		"for (let i = 0; true; ++i) { i }",
+		"let loop = fn() { loop() }; loop()",
+	}
+	for _, input := range inputs {
+		ResetSteps()
+		result := testEval(input)
+		if err, ok := result.(*object.Error); !ok || err.Message != "step limit of 1000 exceeded" {
+			t.Errorf("%q: expected step limit error. got=%v", input, result)
+		}
+	}
+
+	ResetSteps()
+	testIntegerObject(t, testEval("let x = 1; x + 1"), 2)
+}
+
+func TestTailCallStackTrace(t *testing.T) {
+	input := `let inner = fn(x) { x + "one" };
+let outer = fn(x) { inner(x) };
+outer(1);`
+
+	errObj, ok := testEval(input).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	// 末尾呼び出しでもフレームは残る
+	expected := []object.Frame{
+		{Function: "inner", Line: 2, Column: 26},
+		{Function: "outer", Line: 3, Column: 6},
+	}
+	if len(errObj.Stack) != len(expected) {
+		t.Fatalf("wrong stack depth. expected=%d, got=%d (%+v)", len(expected), len(errObj.Stack), errObj.Stack)
+	}
+	for i, frame := range expected {
+		if errObj.Stack[i] != frame {
+			t.Errorf("stack[%d] wrong. expected=%+v, got=%+v", i, frame, errObj.Stack[i])
+		}
+	}
+
+	// 深い末尾再帰では新しい maxTailFrames 個だけが残り、残りは数で示される
+	deep, ok := testEval(`let f = fn(n) { if (n == 0) { n + "x" } else { f(n - 1) } };
+f(1000);`).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned for deep recursion")
+	}
+	if len(deep.Stack) != maxTailFrames+2 {
+		t.Fatalf("wrong stack depth. expected=%d, got=%d", maxTailFrames+2, len(deep.Stack))
+	}
+	for i, frame := range deep.Stack[:maxTailFrames] {
+		if frame.Function != "f" || frame.Line != 1 {
+			t.Errorf("stack[%d] wrong. got=%+v", i, frame)
+		}
+	}
+	omitted := object.Frame{Omitted: 1000 - maxTailFrames}
+	if deep.Stack[maxTailFrames] != omitted {
+		t.Errorf("wrong omitted frame. expected=%+v, got=%+v", omitted, deep.Stack[maxTailFrames])
+	}
+	last := object.Frame{Function: "f", Line: 2, Column: 2}
+	if deep.Stack[maxTailFrames+1] != last {
+		t.Errorf("wrong outermost frame. expected=%+v, got=%+v", last, deep.Stack[maxTailFrames+1])
+	}
+}
+
+func TestResolvedPrograms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let add = fn(a, b) { a + b }; add(2, 3)", 5},
+		{"let adder = fn(x) { fn(y) { x + y } }; adder(2)(3)", 5},
+		{"let f = fn(a, b = a * 2, ...rest) { a + b + len(rest) }; f(1) + f(1, 1, 9, 9)", 7},
+		{"let f = fn() { let g = fn() { x }; let x = 7; g() }; f()", 7},
+		{"let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } }; count(100000)", 0},
+		{"let f = fn(n) { let sum = 0; for (let i = 0; i < n; ++i) { let sum = sum + i; } sum }; f(3)", 0},
+		{"let f = fn(n) { let total = 0; for (let i = 0; i < n; ++i) { ++total; } total }; f(4)", 4},
+		{"let f = fn() { fn fact(n) { if (n < 2) { 1 } else { n * fact(n - 1) } } fact(5) }; f()", 120},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		r := resolver.New(BuiltinNames()...)
+		r.Resolve(program)
+		if len(r.Errors()) != 0 {
+			t.Fatalf("%q: resolver has errors: %v", tt.input, r.Errors())
+		}
+		testIntegerObject(t, Eval(program, object.NewEnvironment()), tt.expected)
+	}
+}
+
+func TestTypeAnnotationsAreIgnored(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x: int = 5; x", 5},
+		{"let add = fn(a: int, b: int = 2): int { a + b }; add(1)", 3},
+		{"let f = fn(...xs: [int]): int { len(xs) }; f(1, 2, 3)", 3},
+		{"fn twice(f: fn(int): int, x: int): int { f(f(x)) } twice(fn(n: int): int { n * 2 }, 3)", 12},
+		// 型注釈と値が合わなくても実行時には検査しない
+		{`let x: string = 7; x`, 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
@@ -0,0 +1,130 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func _builtinSprintf(args ...object.Object) object.Object {
+	if len(args) == 0 || args[0].Type() != object.STRING_OBJ {
+		return newError("first argument to `sprintf` must be a format STRING")
+	}
+	s, err := format(args[0].(*object.String).Value, args[1:])
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: s}
+}
+
+func _builtinPrintf(args ...object.Object) object.Object {
+	if len(args) == 0 || args[0].Type() != object.STRING_OBJ {
+		return newError("first argument to `printf` must be a format STRING")
+	}
+	s, err := format(args[0].(*object.String).Value, args[1:])
+	if err != nil {
+		return err
+	}
+	io.WriteString(Stdout, s)
+	return NULL
+}
+
+// format は Go の fmt と同じ書式 (%[flags][width][.precision]verb) を解釈する.
+// 対応する verb は %d %s %v %x %X %q %t %f %e %g と %%.
+func format(f string, args []object.Object) (string, *object.Error) {
+	var out bytes.Buffer
+	argIdx := 0
+
+	for i := 0; i < len(f); i++ {
+		if f[i] != '%' {
+			out.WriteByte(f[i])
+			continue
+		}
+
+		// flags, width, precision を読み飛ばして verb を探す
+		start := i
+		i++
+		for i < len(f) && bytes.IndexByte([]byte("-+# 0"), f[i]) >= 0 {
+			i++
+		}
+		for i < len(f) && isDigit(f[i]) {
+			i++
+		}
+		if i < len(f) && f[i] == '.' {
+			i++
+			for i < len(f) && isDigit(f[i]) {
+				i++
+			}
+		}
+		if i >= len(f) {
+			return "", newError("format %q ends with an incomplete verb", f)
+		}
+
+		spec := f[start : i+1]
+		verb := f[i]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if argIdx >= len(args) {
+			return "", newError("missing argument for %s in format %q", spec, f)
+		}
+
+		value, err := formatValue(spec, verb, args[argIdx])
+		if err != nil {
+			return "", err
+		}
+		argIdx++
+		out.WriteString(fmt.Sprintf(spec, value))
+	}
+
+	if argIdx < len(args) {
+		return "", newError("too many arguments for format %q. got=%d, want=%d", f, len(args), argIdx)
+	}
+	return out.String(), nil
+}
+
+// formatValue converts arg to the Go value fmt expects for verb.
+func formatValue(spec string, verb byte, arg object.Object) (interface{}, *object.Error) {
+	switch verb {
+	case 'v':
+		return arg.Inspect(), nil
+	case 's':
+		if str, ok := arg.(*object.String); ok {
+			return str.Value, nil
+		}
+		return arg.Inspect(), nil
+	case 'd':
+		if i, ok := arg.(*object.Integer); ok {
+			return i.Value, nil
+		}
+	case 'x', 'X':
+		switch arg := arg.(type) {
+		case *object.Integer:
+			return arg.Value, nil
+		case *object.String:
+			return arg.Value, nil
+		}
+	case 'q':
+		if str, ok := arg.(*object.String); ok {
+			return str.Value, nil
+		}
+	case 't':
+		if b, ok := arg.(*object.Boolean); ok {
+			return b.Value, nil
+		}
+	case 'f', 'e', 'g':
+		if isNumber(arg) {
+			return toFloat(arg), nil
+		}
+	default:
+		return nil, newError("unknown format verb %s", spec)
+	}
+	return nil, newError("bad argument for %s: %s", spec, arg.Type())
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
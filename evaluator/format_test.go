@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func TestSprintf(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`sprintf("plain")`, "plain"},
+		{`sprintf("%d%%", 50)`, "50%"},
+		{`sprintf("[%5d|%-5d|%05d]", 42, 42, 42)`, "[   42|42   |00042]"},
+		{`sprintf("%s and %s", "monkey", 1)`, "monkey and 1"},
+		{`sprintf("[%-6s|%6.2s]", "ab", "xyz")`, "[ab    |    xy]"},
+		{`sprintf("%x %X %x", 255, 255, "hi")`, "ff FF 6869"},
+		{`sprintf("%q", "a\"b")`, `"a\"b"`},
+		{`sprintf("%t", 1 < 2)`, "true"},
+		{`sprintf("%.2f %g", 3.14159, 0.5)`, "3.14 0.5"},
+		{`sprintf("%v %v", [1, "a", true], {"k": [2]})`, `[1, a, true] {k: [2]}`},
+		{`sprintf("%v", fn(x) { x })`, "fn(x) {\nx\n}"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSprintfErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`sprintf(1)`, "first argument to `sprintf` must be a format STRING"},
+		{`sprintf("%d", "one")`, "bad argument for %d: STRING"},
+		{`sprintf("%t", 1)`, "bad argument for %t: INTEGER"},
+		{`sprintf("%d %d", 1)`, `missing argument for %d in format "%d %d"`},
+		{`sprintf("%d", 1, 2)`, `too many arguments for format "%d". got=2, want=1`},
+		{`sprintf("%y", 1)`, "unknown format verb %y"},
+		{`sprintf("100%")`, `format "100%" ends with an incomplete verb`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestPrintfAndPuts(t *testing.T) {
+	defer func(old io.Writer) { Stdout = old }(Stdout)
+	var out bytes.Buffer
+	Stdout = &out
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`printf("%s=%03d\n", "x", 7)`, "x=007\n"},
+		{`puts("a", 1, true)`, "a 1 true\n"},
+		{`puts([1, 2], {"k": "v"}, fn(x) { x })`, "[1, 2] {k: v} fn(x) {\nx\n}\n"},
+		{`puts(if (false) { 1 })`, "null\n"},
+	}
+
+	for _, tt := range tests {
+		out.Reset()
+		evaluated := testEval(tt.input)
+		testNullObject(t, evaluated)
+		if out.String() != tt.expected {
+			t.Errorf("wrong output. expected=%q, got=%q", tt.expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/internal/fuzzseed"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/resolver"
+)
+
+// FuzzEval evaluates programs with a step limit, so that every input
+// terminates, and checks that none of them panics.
+func FuzzEval(f *testing.F) {
+	fuzzseed.Add(f,
+		"1 / 0", "1 % 0", "let f = fn(a, b) { a }; f()", "fn(...xs) { xs }(1, 2)",
+		"let f = fn(n) { f(n + 1) }; f(0)", "for (let i = 0; true; ++i) { i }",
+		`sprintf("%d %s", 1)`, `json_parse("[1, {\"a\": null}]")`, `regex("(")`,
+		`json_stringify([1], -1)`,
+	)
+
+	MaxSteps, Stdout, Exit = 10000, ioutil.Discard, func(int) {}
+	defer func() { MaxSteps, Stdout, Exit = 0, os.Stdout, os.Exit }()
+
+	f.Fuzz(func(t *testing.T, input string) {
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			return
+		}
+		// モジュールはファイルを読んだり time.sleep で止まったりする
+		for _, stmt := range program.Statements {
+			if _, ok := stmt.(*ast.ImportStatement); ok {
+				return
+			}
+		}
+		r := resolver.New(BuiltinNames()...)
+		r.Resolve(program)
+		if len(r.Errors()) != 0 {
+			return
+		}
+
+		ResetSteps()
+		Eval(program, object.NewEnvironment())
+	})
+}
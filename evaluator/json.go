@@ -0,0 +1,174 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func _builtinJSONParse(args ...object.Object) object.Object {
+	if err := checkArgs("json_parse", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(strings.NewReader(args[0].(*object.String).Value))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return newError("invalid JSON: %s", err)
+	}
+	if dec.More() {
+		return newError("invalid JSON: unexpected data after top-level value")
+	}
+	return fromJSON(v)
+}
+
+func fromJSON(v interface{}) object.Object {
+	switch v := v.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return nativeBoolToBooleanObject(v)
+	case string:
+		return &object.String{Value: v}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &object.Integer{Value: i}
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return newError("invalid JSON number: %s", v)
+		}
+		return &object.Float{Value: f}
+	case []interface{}:
+		elements := make([]object.Object, len(v))
+		for i, el := range v {
+			elements[i] = fromJSON(el)
+			if isError(elements[i]) {
+				return elements[i]
+			}
+		}
+		return &object.Array{Elements: elements}
+	case map[string]interface{}:
+		pairs := make(map[object.HashKey]object.HashPair)
+		for key, val := range v {
+			k := &object.String{Value: key}
+			value := fromJSON(val)
+			if isError(value) {
+				return value
+			}
+			pairs[k.HashKey()] = object.HashPair{Key: k, Value: value}
+		}
+		return &object.Hash{Pairs: pairs}
+	default:
+		return newError("unsupported JSON value: %T", v)
+	}
+}
+
+// maxJSONIndent is the largest number of spaces json_stringify indents by.
+const maxJSONIndent = 10
+
+// json_stringify(obj) encodes compactly. json_stringify(obj, indent)
+// indents nested values by indent spaces (INTEGER) or by the given STRING.
+// Hash keys are emitted in sorted order so that the output is stable.
+func _builtinJSONStringify(args ...object.Object) object.Object {
+	indent := ""
+	switch len(args) {
+	case 1:
+	case 2:
+		switch arg := args[1].(type) {
+		case *object.Integer:
+			if arg.Value < 0 || arg.Value > maxJSONIndent {
+				return newError("argument 2 to `json_stringify` must be between 0 and %d, got %d", maxJSONIndent, arg.Value)
+			}
+			indent = strings.Repeat(" ", int(arg.Value))
+		case *object.String:
+			indent = arg.Value
+		default:
+			return newError("argument 2 to `json_stringify` must be INTEGER or STRING, got %s", arg.Type())
+		}
+	default:
+		return newError("wrong number of arguments to `json_stringify`. got=%d, want=1 or 2", len(args))
+	}
+
+	var out bytes.Buffer
+	if err := writeJSON(&out, args[0]); err != nil {
+		return err
+	}
+	if indent == "" {
+		return &object.String{Value: out.String()}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, out.Bytes(), "", indent); err != nil {
+		return newError("could not indent JSON: %s", err)
+	}
+	return &object.String{Value: indented.String()}
+}
+
+func writeJSON(out *bytes.Buffer, obj object.Object) *object.Error {
+	switch obj := obj.(type) {
+	case *object.NULL:
+		out.WriteString("null")
+	case *object.Boolean, *object.Integer:
+		out.WriteString(obj.Inspect())
+	case *object.Float:
+		if math.IsInf(obj.Value, 0) || math.IsNaN(obj.Value) {
+			return newError("cannot encode %s as JSON", obj.Inspect())
+		}
+		out.WriteString(obj.Inspect())
+	case *object.String:
+		writeJSONString(out, obj.Value)
+	case *object.Array:
+		out.WriteString("[")
+		for i, el := range obj.Elements {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			if err := writeJSON(out, el); err != nil {
+				return err
+			}
+		}
+		out.WriteString("]")
+	case *object.Hash:
+		keys := []string{}
+		values := map[string]object.Object{}
+		for _, pair := range obj.Pairs {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return newError("unusable as JSON object key: %s", pair.Key.Type())
+			}
+			keys = append(keys, key.Value)
+			values[key.Value] = pair.Value
+		}
+		sort.Strings(keys)
+
+		out.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			writeJSONString(out, key)
+			out.WriteString(":")
+			if err := writeJSON(out, values[key]); err != nil {
+				return err
+			}
+		}
+		out.WriteString("}")
+	default:
+		return newError("cannot encode %s as JSON", obj.Type())
+	}
+	return nil
+}
+
+func writeJSONString(out *bytes.Buffer, s string) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// Encode は末尾に改行を付けるので取り除く
+	out.Truncate(out.Len() - 1)
+}
@@ -0,0 +1,103 @@
+package evaluator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func TestJSONParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`json_parse("42")`, 42},
+		{`json_parse("-7")`, -7},
+		{`json_parse("1.5")`, 1.5},
+		{`json_parse("true")`, true},
+		{`json_parse("null")`, nil},
+		{`json_parse("\"monkey\"")`, "monkey"},
+		{`len(json_parse("[1, 2, [3]]"))`, 3},
+		{`json_parse("[1, 2, [3]]")[2][0]`, 3},
+		{`json_parse("{\"a\": {\"b\": [true]}}")["a"]["b"][0]`, true},
+		{`json_parse("{\"name\": \"monkey\"}").name`, "monkey"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json_stringify(1)`, `1`},
+		{`json_stringify(2.5)`, `2.5`},
+		{`json_stringify("a\"b<c>")`, `"a\"b<c>"`},
+		{`json_stringify(if (false) { 1 })`, `null`},
+		{`json_stringify([1, "two", true, [ ]])`, `[1,"two",true,[]]`},
+		{`json_stringify({"b": 1, "a": 2, "c": {"z": 0, "y": 1}})`, `{"a":2,"b":1,"c":{"y":1,"z":0}}`},
+		{`json_stringify({"a": [1, 2]}, 2)`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{`json_stringify([1], "\t")`, "[\n\t1\n]"},
+		{`json_stringify([1], 10)`, "[\n          1\n]"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	inputs := []string{
+		`{"a":[1,2,{"b":null}],"c":"d\\\"e","e":false,"f":-9007199254740993,"g":0.25}`,
+		`[[],{},"",0]`,
+	}
+
+	for _, in := range inputs {
+		input := "json_stringify(json_parse(json_stringify(json_parse(" + strconv.Quote(in) + "))))"
+		testStringObject(t, testEval(input), in)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`json_parse("{")`, "invalid JSON: unexpected EOF"},
+		{`json_parse("1 2")`, "invalid JSON: unexpected data after top-level value"},
+		{`json_stringify(fn(x) { x })`, "cannot encode FUNCTION as JSON"},
+		{`json_stringify([len])`, "cannot encode BUILTIN as JSON"},
+		{`json_stringify({1: "one"})`, "unusable as JSON object key: INTEGER"},
+		{`json_stringify(1, true)`, "argument 2 to `json_stringify` must be INTEGER or STRING, got BOOLEAN"},
+		{`json_stringify(1, -1)`, "argument 2 to `json_stringify` must be between 0 and 10, got -1"},
+		{`json_stringify(1, 9223372036854775807)`, "argument 2 to `json_stringify` must be between 0 and 10, got 9223372036854775807"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/resolver"
+)
+
+// ModuleExt is the extension appended to import paths that have none.
+const ModuleExt = ".monkey"
+
+// SearchPath lists the directories consulted, after the directory of the
+// importing file, when resolving a relative import path.
+var SearchPath []string
+
+var (
+	// 評価済みモジュールのキャッシュ (絶対パス => モジュール)
+	modules = map[string]*object.Module{}
+	// 評価中のモジュール. 循環importの検出に使う
+	loading []string
+)
+
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	module := importModule(node.Path.Value, env)
+	if isError(module) {
+		return module
+	}
+	mod := module.(*object.Module)
+
+	if node.Names == nil {
+		name := mod.Name
+		if node.Alias != nil {
+			name = node.Alias.Value
+		} else if !isIdentifier(name) {
+			return newError("cannot bind module %q to a name, use `import %q as <name>`", node.Path.Value, node.Path.Value)
+		}
+		env.Set(name, mod)
+		return mod
+	}
+
+	for _, n := range node.Names {
+		val, ok := mod.Exports[n.Name.Value]
+		if !ok {
+			return newError("module %s has no export named %s", mod.Name, n.Name.Value)
+		}
+		env.Set(n.Binding().Value, val)
+	}
+	return mod
+}
+
+func importModule(path string, env *object.Environment) object.Object {
+	if mod, ok := nativeModules[path]; ok {
+		return mod
+	}
+
+	file, ok := resolveModulePath(path, env.File())
+	if !ok {
+		return newError("module not found: %q", path)
+	}
+
+	if mod, ok := modules[file]; ok {
+		return mod
+	}
+	for i, f := range loading {
+		if f == file {
+			cycle := append(append([]string{}, loading[i:]...), file)
+			return newError("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	loading = append(loading, file)
+	defer func() { loading = loading[:len(loading)-1] }()
+
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return newError("could not read module %q: %s", path, err)
+	}
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return newError("could not parse module %q: %s", path, strings.Join(p.Errors(), "; "))
+	}
+	r := resolver.New(BuiltinNames()...)
+	r.Resolve(program)
+	if len(r.Errors()) != 0 {
+		return newError("could not resolve module %q: %s", path, strings.Join(r.Errors(), "; "))
+	}
+	if Coverage != nil {
+		Coverage.Add(file, src, program)
+	}
+
+	moduleEnv := object.NewEnvironment()
+	moduleEnv.SetFile(file)
+	if result := Eval(program, moduleEnv); isError(result) {
+		return result
+	}
+
+	mod := newModule(file, program, moduleEnv)
+	modules[file] = mod
+	return mod
+}
+
+// newModule returns the module of file with the exports of program, which
+// was evaluated in env.
+func newModule(file string, program *ast.Program, env *object.Environment) *object.Module {
+	mod := &object.Module{
+		Name:    strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+		Path:    file,
+		Exports: map[string]object.Object{},
+	}
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			name := export.Statement.Name.Value
+			if val, ok := env.Get(name); ok {
+				mod.Exports[name] = val
+			}
+		}
+	}
+	return mod
+}
+
+// EvalScript evaluates program, the script in the file of env, as Eval
+// does. While it runs the script counts as a module being loaded, so that
+// a module importing it back is an import cycle instead of evaluating the
+// script a second time, and afterwards it is cached as a module. Scripts
+// read from standard input have no file and are evaluated as they are.
+func EvalScript(program *ast.Program, env *object.Environment) object.Object {
+	file, err := filepath.Abs(env.File())
+	if env.File() == "" || err != nil {
+		return Eval(program, env)
+	}
+
+	loading = append(loading, file)
+	defer func() { loading = loading[:len(loading)-1] }()
+	result := Eval(program, env)
+	if !isError(result) {
+		modules[file] = newModule(file, program, env)
+	}
+	return result
+}
+
+// resolveModulePath finds the file an import refers to. Relative paths are
+// tried against the directory of the importing file (or the working
+// directory), then against each SearchPath entry.
+func resolveModulePath(path, from string) (string, bool) {
+	if filepath.Ext(path) == "" {
+		path += ModuleExt
+	}
+
+	candidates := []string{}
+	if filepath.IsAbs(path) {
+		candidates = append(candidates, path)
+	} else {
+		dir := "."
+		if from != "" {
+			dir = filepath.Dir(from)
+		}
+		candidates = append(candidates, filepath.Join(dir, path))
+		for _, d := range SearchPath {
+			candidates = append(candidates, filepath.Join(d, path))
+		}
+	}
+
+	for _, c := range candidates {
+		info, err := os.Stat(c)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		abs, err := filepath.Abs(c)
+		if err != nil {
+			continue
+		}
+		return abs, true
+	}
+	return "", false
+}
+
+func evalDotExpression(node *ast.DotExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	switch left := left.(type) {
+	case *object.Module:
+		val, ok := left.Exports[node.Name.Value]
+		if !ok {
+			return newError("module %s has no export named %s", left.Name, node.Name.Value)
+		}
+		return val
+	case *object.Hash:
+		return evalHashIndexExpression(left, &object.String{Value: node.Name.Value})
+	case *object.Regex:
+		return regexMethod(left, node.Name.Value)
+	default:
+		return newError("member access not supported: %s.%s", left.Type(), node.Name.Value)
+	}
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	// lexer と同じく2文字目以降は数字でもよい
+	for i, ch := range name {
+		letter := 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+		if !letter && !(i > 0 && '0' <= ch && ch <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func testEvalFile(t *testing.T, path string) object.Object {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read %s: %s", path, err)
+	}
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors in %s: %v", path, p.Errors())
+	}
+	env := object.NewEnvironment()
+	env.SetFile(path)
+
+	return EvalScript(program, env)
+}
+
+func TestImportModule(t *testing.T) {
+	evaluated := testEvalFile(t, "../testdata/modules/main.monkey")
+	testIntegerObject(t, evaluated, 13)
+}
+
+func TestImportEvaluatesModuleOnce(t *testing.T) {
+	input := `
+	import "../testdata/modules/lib/mathx.monkey" as a;
+	import "../testdata/modules/lib/mathx" as b;
+	a == b;
+	`
+	testBooleanObject(t, testEval(input), true)
+}
+
+func TestImportModuleWithDigitsInName(t *testing.T) {
+	evaluated := testEval(`import "../testdata/modules/lib/vec2"; vec2.add([1, 2], [3, 4])[1]`)
+	testIntegerObject(t, evaluated, 6)
+	if _, ok := testEval(`import "../testdata/modules/lib/vec2"; vec2`).(*object.Module); !ok {
+		t.Errorf("module vec2 not bound to its name")
+	}
+}
+
+func TestImportedModuleExports(t *testing.T) {
+	evaluated := testEval(`import "../testdata/modules/lib/mathx.monkey"; mathx`)
+	mod, ok := evaluated.(*object.Module)
+	if !ok {
+		t.Fatalf("object is not Module. got=%T (%+v)", evaluated, evaluated)
+	}
+	if mod.Name != "mathx" {
+		t.Errorf("module has wrong name. got=%q", mod.Name)
+	}
+	for _, name := range []string{"add", "double", "square"} {
+		if _, ok := mod.Exports[name]; !ok {
+			t.Errorf("module does not export %q", name)
+		}
+	}
+	if _, ok := mod.Exports["hidden"]; ok {
+		t.Errorf("module exports unexported binding %q", "hidden")
+	}
+}
+
+func TestImportSearchPath(t *testing.T) {
+	defer func(old []string) { SearchPath = old }(SearchPath)
+	SearchPath = []string{filepath.Join("..", "testdata", "modules", "vendor")}
+
+	evaluated := testEvalFile(t, "../testdata/modules/searchpath.monkey")
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+	if str.Value != "Hello, monkey" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{
+			`import "../testdata/modules/missing.monkey";`,
+			`module not found: "../testdata/modules/missing.monkey"`,
+		},
+		{
+			`import { hidden } from "../testdata/modules/lib/mathx";`,
+			"module mathx has no export named hidden",
+		},
+		{
+			`import "../testdata/modules/lib/mathx" as m; m.hidden`,
+			"module mathx has no export named hidden",
+		},
+		{
+			`let x = 5; x.y`,
+			"member access not supported: INTEGER.y",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestImportCycle(t *testing.T) {
+	// 実行中のスクリプト自身も循環に含まれ, 二度評価されない
+	evaluated := testEvalFile(t, "../testdata/modules/cycle_a.monkey")
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if !strings.HasPrefix(errObj.Message, "import cycle: ") {
+		t.Fatalf("wrong error message. got=%q", errObj.Message)
+	}
+	files := strings.Split(strings.TrimPrefix(errObj.Message, "import cycle: "), " -> ")
+	expected := []string{"cycle_a.monkey", "cycle_b.monkey", "cycle_a.monkey"}
+	if len(files) != len(expected) {
+		t.Fatalf("cycle has wrong length. got=%v", files)
+	}
+	for i, f := range files {
+		if filepath.Base(f) != expected[i] {
+			t.Errorf("cycle[%d] wrong. expected=%q, got=%q", i, expected[i], f)
+		}
+	}
+}
+
+func TestDotExpressionOnHash(t *testing.T) {
+	testIntegerObject(t, testEval(`let h = {"one": 1}; h.one`), 1)
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+// Go で実装されたモジュール (import "strings" などで読み込む)
+var nativeModules = map[string]*object.Module{}
+
+// RegisterModule makes a module implemented in Go importable under name.
+// Members are usually *object.Builtin values, but constants such as
+// math.pi can be registered as any object. Registering a name twice
+// replaces the earlier module.
+func RegisterModule(name string, members map[string]object.Object) {
+	for member, obj := range members {
+		if builtin, ok := obj.(*object.Builtin); ok && builtin.Name == "" {
+			builtin.Name = name + "." + member
+		}
+	}
+	nativeModules[name] = &object.Module{
+		Name:    name,
+		Exports: members,
+	}
+}
+
+// NativeModules returns the names of all registered native modules.
+func NativeModules() []string {
+	names := []string{}
+	for name := range nativeModules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkArgs validates the number and types of arguments passed to the
+// native function name. An empty type accepts any object.
+func checkArgs(name string, args []object.Object, types ...object.ObjectType) *object.Error {
+	if len(args) != len(types) {
+		return newError("wrong number of arguments to `%s`. got=%d, want=%d", name, len(args), len(types))
+	}
+	for i, t := range types {
+		if t != "" && args[i].Type() != t {
+			return newError("argument %d to `%s` must be %s, got %s", i+1, name, t, args[i].Type())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func init() {
+	RegisterModule("math", map[string]object.Object{
+		"pi":    &object.Float{Value: math.Pi},
+		"e":     &object.Float{Value: math.E},
+		"abs":   &object.Builtin{Fn: _mathAbs},
+		"min":   &object.Builtin{Fn: _mathMin},
+		"max":   &object.Builtin{Fn: _mathMax},
+		"pow":   &object.Builtin{Fn: _mathPow},
+		"sqrt":  &object.Builtin{Fn: mathFunc("sqrt", math.Sqrt)},
+		"sin":   &object.Builtin{Fn: mathFunc("sin", math.Sin)},
+		"cos":   &object.Builtin{Fn: mathFunc("cos", math.Cos)},
+		"tan":   &object.Builtin{Fn: mathFunc("tan", math.Tan)},
+		"asin":  &object.Builtin{Fn: mathFunc("asin", math.Asin)},
+		"acos":  &object.Builtin{Fn: mathFunc("acos", math.Acos)},
+		"atan":  &object.Builtin{Fn: mathFunc("atan", math.Atan)},
+		"floor": &object.Builtin{Fn: mathRound("floor", math.Floor)},
+		"ceil":  &object.Builtin{Fn: mathRound("ceil", math.Ceil)},
+		"round": &object.Builtin{Fn: mathRound("round", math.Round)},
+	})
+}
+
+func checkNumbers(name string, args []object.Object) *object.Error {
+	for i, arg := range args {
+		if !isNumber(arg) {
+			return newError("argument %d to `%s` must be INTEGER or FLOAT, got %s", i+1, name, arg.Type())
+		}
+	}
+	return nil
+}
+
+func _mathAbs(args ...object.Object) object.Object {
+	if err := checkArgs("abs", args, ""); err != nil {
+		return err
+	}
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		if arg.Value < 0 {
+			return &object.Integer{Value: -arg.Value}
+		}
+		return arg
+	case *object.Float:
+		return &object.Float{Value: math.Abs(arg.Value)}
+	default:
+		return newError("argument 1 to `abs` must be INTEGER or FLOAT, got %s", arg.Type())
+	}
+}
+
+func _mathMin(args ...object.Object) object.Object {
+	return extremum("min", args, func(a, b float64) bool { return a < b })
+}
+
+func _mathMax(args ...object.Object) object.Object {
+	return extremum("max", args, func(a, b float64) bool { return a > b })
+}
+
+// extremum returns the argument (or element of a single array argument)
+// preferred by better, keeping its original INTEGER or FLOAT type.
+func extremum(name string, args []object.Object, better func(a, b float64) bool) object.Object {
+	if len(args) == 1 {
+		if arr, ok := args[0].(*object.Array); ok {
+			args = arr.Elements
+		}
+	}
+	if len(args) == 0 {
+		return newError("`%s` needs at least one argument", name)
+	}
+	if err := checkNumbers(name, args); err != nil {
+		return err
+	}
+
+	result := args[0]
+	for _, arg := range args[1:] {
+		if better(toFloat(arg), toFloat(result)) {
+			result = arg
+		}
+	}
+	return result
+}
+
+// pow(INTEGER, INTEGER) stays an INTEGER for non-negative exponents,
+// anything else is computed as FLOAT.
+func _mathPow(args ...object.Object) object.Object {
+	if err := checkArgs("pow", args, "", ""); err != nil {
+		return err
+	}
+	if err := checkNumbers("pow", args); err != nil {
+		return err
+	}
+
+	base, baseIsInt := args[0].(*object.Integer)
+	exp, expIsInt := args[1].(*object.Integer)
+	if baseIsInt && expIsInt && exp.Value >= 0 {
+		result, ok := powInt(base.Value, exp.Value)
+		if !ok {
+			return newError("pow(%d, %d) overflows INTEGER", base.Value, exp.Value)
+		}
+		return &object.Integer{Value: result}
+	}
+	return &object.Float{Value: math.Pow(toFloat(args[0]), toFloat(args[1]))}
+}
+
+// powInt computes base to the power exp by squaring, so in time
+// proportional to the number of bits of exp. It reports false if the
+// result does not fit in an int64.
+func powInt(base, exp int64) (int64, bool) {
+	result := int64(1)
+	for exp > 0 {
+		var ok bool
+		if exp&1 == 1 {
+			if result, ok = mulInt(result, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		// 残りの指数があるときだけ二乗する. 溢れたら結果も溢れる
+		if exp > 0 {
+			if base, ok = mulInt(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// mulInt multiplies a and b, reporting false on overflow.
+func mulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || a == -1 && b == math.MinInt64 || b == -1 && a == math.MinInt64 {
+		return 0, false
+	}
+	return c, true
+}
+
+func mathFunc(name string, fn func(float64) float64) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if err := checkArgs(name, args, ""); err != nil {
+			return err
+		}
+		if err := checkNumbers(name, args); err != nil {
+			return err
+		}
+		return &object.Float{Value: fn(toFloat(args[0]))}
+	}
+}
+
+// mathRound wraps a rounding function so that it returns an INTEGER.
+func mathRound(name string, fn func(float64) float64) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if err := checkArgs(name, args, ""); err != nil {
+			return err
+		}
+		if err := checkNumbers(name, args); err != nil {
+			return err
+		}
+		return &object.Integer{Value: int64(fn(toFloat(args[0])))}
+	}
+}
@@ -0,0 +1,44 @@
+package evaluator
+
+import (
+	"os"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+// Args holds the arguments of the running script, as returned by os.args().
+var Args []string
+
+// Exit is called by exit() and os.exit() after flushing Stdout. Embedders
+// can replace it to keep the host process alive.
+var Exit = os.Exit
+
+func init() {
+	RegisterModule("os", map[string]object.Object{
+		"env":  &object.Builtin{Fn: _osEnv},
+		"args": &object.Builtin{Fn: _osArgs},
+		"exit": &object.Builtin{Fn: _builtinExit},
+	})
+}
+
+func _osEnv(args ...object.Object) object.Object {
+	if err := checkArgs("env", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	val, ok := os.LookupEnv(args[0].(*object.String).Value)
+	if !ok {
+		return NULL
+	}
+	return &object.String{Value: val}
+}
+
+func _osArgs(args ...object.Object) object.Object {
+	if err := checkArgs("args", args); err != nil {
+		return err
+	}
+	elements := make([]object.Object, len(Args))
+	for i, a := range Args {
+		elements[i] = &object.String{Value: a}
+	}
+	return &object.Array{Elements: elements}
+}
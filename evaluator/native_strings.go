@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func init() {
+	RegisterModule("strings", map[string]object.Object{
+		"upper":       &object.Builtin{Fn: _stringsUpper},
+		"lower":       &object.Builtin{Fn: _stringsLower},
+		"trim":        &object.Builtin{Fn: _stringsTrim},
+		"replace":     &object.Builtin{Fn: _stringsReplace},
+		"split":       &object.Builtin{Fn: _stringsSplit},
+		"join":        &object.Builtin{Fn: _stringsJoin},
+		"contains":    &object.Builtin{Fn: _stringsContains},
+		"starts_with": &object.Builtin{Fn: _stringsStartsWith},
+		"ends_with":   &object.Builtin{Fn: _stringsEndsWith},
+		"pad_left":    &object.Builtin{Fn: _stringsPadLeft},
+		"pad_right":   &object.Builtin{Fn: _stringsPadRight},
+	})
+}
+
+func _stringsUpper(args ...object.Object) object.Object {
+	if err := checkArgs("upper", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToUpper(args[0].(*object.String).Value)}
+}
+
+func _stringsLower(args ...object.Object) object.Object {
+	if err := checkArgs("lower", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToLower(args[0].(*object.String).Value)}
+}
+
+// trim(s) removes surrounding whitespace, trim(s, cutset) the given characters.
+func _stringsTrim(args ...object.Object) object.Object {
+	if len(args) == 2 {
+		if err := checkArgs("trim", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return &object.String{Value: strings.Trim(args[0].(*object.String).Value, args[1].(*object.String).Value)}
+	}
+	if err := checkArgs("trim", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	return &object.String{Value: strings.TrimSpace(args[0].(*object.String).Value)}
+}
+
+func _stringsReplace(args ...object.Object) object.Object {
+	if err := checkArgs("replace", args, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	s := args[0].(*object.String).Value
+	old := args[1].(*object.String).Value
+	new := args[2].(*object.String).Value
+	return &object.String{Value: strings.Replace(s, old, new, -1)}
+}
+
+func _stringsSplit(args ...object.Object) object.Object {
+	if err := checkArgs("split", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	parts := strings.Split(args[0].(*object.String).Value, args[1].(*object.String).Value)
+	elements := make([]object.Object, len(parts))
+	for i, part := range parts {
+		elements[i] = &object.String{Value: part}
+	}
+	return &object.Array{Elements: elements}
+}
+
+func _stringsJoin(args ...object.Object) object.Object {
+	if err := checkArgs("join", args, object.ARRAY_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	elements := args[0].(*object.Array).Elements
+	parts := make([]string, len(elements))
+	for i, el := range elements {
+		str, ok := el.(*object.String)
+		if !ok {
+			return newError("argument to `join` must be ARRAY of STRING, got %s at index %d", el.Type(), i)
+		}
+		parts[i] = str.Value
+	}
+	return &object.String{Value: strings.Join(parts, args[1].(*object.String).Value)}
+}
+
+func _stringsContains(args ...object.Object) object.Object {
+	if err := checkArgs("contains", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.Contains(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+func _stringsStartsWith(args ...object.Object) object.Object {
+	if err := checkArgs("starts_with", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.HasPrefix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+func _stringsEndsWith(args ...object.Object) object.Object {
+	if err := checkArgs("ends_with", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.HasSuffix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+func _stringsPadLeft(args ...object.Object) object.Object {
+	return pad("pad_left", true, args)
+}
+
+func _stringsPadRight(args ...object.Object) object.Object {
+	return pad("pad_right", false, args)
+}
+
+// maxPadWidth is the widest string pad_left and pad_right make.
+const maxPadWidth = 1 << 20
+
+// pad(s, width) pads s with spaces up to width characters,
+// pad(s, width, fill) with the given fill string, repeated and cut to
+// fit.
+func pad(name string, left bool, args []object.Object) object.Object {
+	fill := " "
+	if len(args) == 3 {
+		if err := checkArgs(name, args, object.STRING_OBJ, object.INTEGER_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		fill = args[2].(*object.String).Value
+		if fill == "" {
+			return newError("fill string to `%s` must not be empty", name)
+		}
+	} else if err := checkArgs(name, args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+	width := args[1].(*object.Integer).Value
+	if width > maxPadWidth {
+		return newError("width to `%s` must be at most %d, got %d", name, maxPadWidth, width)
+	}
+	// 最後の fill は幅に収まるよう切り詰める
+	fillRunes := []rune(fill)
+	padding := []rune{}
+	for n := int64(len([]rune(s))); n < width; n++ {
+		padding = append(padding, fillRunes[len(padding)%len(fillRunes)])
+	}
+	if left {
+		return &object.String{Value: string(padding) + s}
+	}
+	return &object.String{Value: s + string(padding)}
+}
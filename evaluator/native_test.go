@@ -0,0 +1,212 @@
+package evaluator
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("object is not String, got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%q, want=%q", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func TestStringsModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "strings"; strings.upper("monkey")`, "MONKEY"},
+		{`import "strings"; strings.lower("MoNkEy")`, "monkey"},
+		{`import "strings" as s; s.trim("  monkey ")`, "monkey"},
+		{`import "strings" as s; s.trim("--monkey--", "-")`, "monkey"},
+		{`import "strings" as s; s.replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`import "strings" as s; len(s.split("a,b,c", ","))`, 3},
+		{`import "strings" as s; s.join(s.split("a,b,c", ","), "|")`, "a|b|c"},
+		{`import "strings" as s; s.contains("monkey", "key")`, true},
+		{`import { starts_with } from "strings"; starts_with("monkey", "mon")`, true},
+		{`import { ends_with } from "strings"; ends_with("monkey", "mon")`, false},
+		{`import "strings" as s; s.pad_left("7", 3, "0")`, "007"},
+		{`import "strings" as s; s.pad_right("ab", 4)`, "ab  "},
+		{`import "strings" as s; s.pad_left("a", 4, "xy")`, "xyxa"},
+		{`import "strings" as s; s.pad_right("a", 4, "xy")`, "axyx"},
+		{`import "strings" as s; s.pad_left("né", 4, "éa")`, "éané"},
+		{`import "strings" as s; s.pad_left("abc", 2)`, "abc"},
+		{`import "strings" as s; s.pad_left("a", 9223372036854775807)`, "width to `pad_left` must be at most 1048576, got 9223372036854775807"},
+		{`import "strings" as s; s.upper(1)`, "argument 1 to `upper` must be STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "math"; math.abs(-5)`, 5},
+		{`import "math"; math.abs(-2.5)`, 2.5},
+		{`import "math"; math.min(3, 1, 2)`, 1},
+		{`import "math"; math.max([3, 1, 2])`, 3},
+		{`import "math"; math.max(1, 2.5)`, 2.5},
+		{`import "math"; math.pow(2, 10)`, 1024},
+		{`import "math"; math.pow(2, -1)`, 0.5},
+		{`import "math"; math.pow(-3, 3)`, -27},
+		{`import "math"; math.pow(2, 62)`, 4611686018427387904},
+		{`import "math"; math.pow(-2, 63)`, math.MinInt64},
+		{`import "math"; math.pow(-1, 9000000000001)`, -1},
+		{`import "math"; math.pow(0, 9000000000000)`, 0},
+		{`import "math"; math.pow(2, 63)`, "pow(2, 63) overflows INTEGER"},
+		{`import "math"; math.pow(2, 9000000000000)`, "pow(2, 9000000000000) overflows INTEGER"},
+		{`import "math"; math.sqrt(16)`, 4.0},
+		{`import "math"; math.sin(0)`, 0.0},
+		{`import "math"; math.cos(0)`, 1.0},
+		{`import "math"; math.floor(2.7)`, 2},
+		{`import "math"; math.round(2.5)`, 3},
+		{`import "math"; math.pi > 3.14 && math.pi < 3.15`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if err, ok := evaluated.(*object.Error); !ok || err.Message != expected {
+				t.Errorf("%s: expected error %q. got=%v", tt.input, expected, evaluated)
+			}
+		}
+	}
+}
+
+func TestTimeModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "time"; time.now() > 0`, true},
+		{`import "time"; time.parse("2006-01-02", "1970-01-02")`, 86400000},
+		{`import "time"; time.format(time.parse("2006-01-02 15:04", "2020-05-06 07:08"), "15:04 02/01/2006")`, "07:08 06/05/2020"},
+		{`import "time"; time.sleep(1)`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestOsModule(t *testing.T) {
+	os.Setenv("MONKEY_TEST_ENV", "banana")
+	defer os.Unsetenv("MONKEY_TEST_ENV")
+	testStringObject(t, testEval(`import "os"; os.env("MONKEY_TEST_ENV")`), "banana")
+	testNullObject(t, testEval(`import "os"; os.env("MONKEY_TEST_UNSET")`))
+
+	defer func(old []string) { Args = old }(Args)
+	Args = []string{"a", "b"}
+	testStringObject(t, testEval(`import "os"; os.args()[1]`), "b")
+
+	defer func(old func(int)) { Exit = old }(Exit)
+	code := -1
+	Exit = func(c int) { code = c }
+	testEval(`import "os"; os.exit(3)`)
+	if code != 3 {
+		t.Errorf("os.exit called with wrong code. got=%d", code)
+	}
+}
+
+func TestRegisterModule(t *testing.T) {
+	RegisterModule("greeter", map[string]object.Object{
+		"hello": &object.Builtin{Fn: func(args ...object.Object) object.Object {
+			return &object.String{Value: "hello " + args[0].Inspect()}
+		}},
+	})
+	defer delete(nativeModules, "greeter")
+
+	testStringObject(t, testEval(`import "greeter" as g; g.hello("embedder")`), "hello embedder")
+}
+
+func TestExitBuiltin(t *testing.T) {
+	defer func(old func(int)) { Exit = old }(Exit)
+	defer func(old io.Writer) { Stdout = old }(Stdout)
+
+	var out bytes.Buffer
+	buffered := bufio.NewWriter(&out)
+	Stdout = buffered
+	code := -1
+	flushed := ""
+	Exit = func(c int) {
+		code = c
+		flushed = out.String()
+	}
+
+	testEval(`puts("bye"); exit(4)`)
+	if code != 4 {
+		t.Errorf("exit called with wrong code. got=%d", code)
+	}
+	if flushed != "bye\n" {
+		t.Errorf("output not flushed before exit. got=%q", flushed)
+	}
+
+	testEval(`exit()`)
+	if code != 0 {
+		t.Errorf("exit() called with wrong code. got=%d", code)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`exit("1")`, "argument 1 to `exit` must be INTEGER, got STRING"},
+		{`exit(1, 2)`, "wrong number of arguments to `exit`. got=2, want=0 or 1"},
+	}
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok || errObj.Message != tt.expected {
+			t.Errorf("%s: wrong result. got=%+v, want=%q", tt.input, errObj, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package evaluator
+
+import (
+	"time"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+// time モジュールの時刻は Unix エポックからのミリ秒 (INTEGER) で表す
+func init() {
+	RegisterModule("time", map[string]object.Object{
+		"now":    &object.Builtin{Fn: _timeNow},
+		"format": &object.Builtin{Fn: _timeFormat},
+		"parse":  &object.Builtin{Fn: _timeParse},
+		"sleep":  &object.Builtin{Fn: _timeSleep},
+	})
+}
+
+func _timeNow(args ...object.Object) object.Object {
+	if err := checkArgs("now", args); err != nil {
+		return err
+	}
+	return &object.Integer{Value: time.Now().UnixNano() / int64(time.Millisecond)}
+}
+
+// format(ms) formats as RFC 3339, format(ms, layout) with a Go time layout.
+// Times are always formatted in UTC, matching parse.
+func _timeFormat(args ...object.Object) object.Object {
+	layout := time.RFC3339
+	if len(args) == 2 {
+		if err := checkArgs("format", args, object.INTEGER_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		layout = args[1].(*object.String).Value
+	} else if err := checkArgs("format", args, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	ms := args[0].(*object.Integer).Value
+	t := time.Unix(0, ms*int64(time.Millisecond)).UTC()
+	return &object.String{Value: t.Format(layout)}
+}
+
+func _timeParse(args ...object.Object) object.Object {
+	if err := checkArgs("parse", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	layout := args[0].(*object.String).Value
+	value := args[1].(*object.String).Value
+
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return newError("could not parse time %q: %s", value, err)
+	}
+	return &object.Integer{Value: t.UnixNano() / int64(time.Millisecond)}
+}
+
+func _timeSleep(args ...object.Object) object.Object {
+	if err := checkArgs("sleep", args, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(args[0].(*object.Integer).Value) * time.Millisecond)
+	return NULL
+}
@@ -0,0 +1,220 @@
+package evaluator
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+// maxCachedRegexes is the number of compiled patterns kept, so that
+// patterns built at run time do not grow the cache without limit.
+const maxCachedRegexes = 256
+
+// コンパイル済み正規表現のキャッシュ. 最後に使われたものが order の先頭
+var regexCache = struct {
+	sync.Mutex
+	patterns map[string]*list.Element // パターン => order の要素
+	order    *list.List               // *regexp.Regexp の並び
+}{patterns: map[string]*list.Element{}, order: list.New()}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	regexCache.Lock()
+	defer regexCache.Unlock()
+
+	if e, ok := regexCache.patterns[pattern]; ok {
+		regexCache.order.MoveToFront(e)
+		return e.Value.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.patterns[pattern] = regexCache.order.PushFront(re)
+	if regexCache.order.Len() > maxCachedRegexes {
+		oldest := regexCache.order.Back()
+		regexCache.order.Remove(oldest)
+		delete(regexCache.patterns, oldest.Value.(*regexp.Regexp).String())
+	}
+	return re, nil
+}
+
+func _builtinRegex(args ...object.Object) object.Object {
+	if err := checkArgs("regex", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	re, err := compileRegex(args[0].(*object.String).Value)
+	if err != nil {
+		return newError("invalid regex: %s", err)
+	}
+	return &object.Regex{Regexp: re}
+}
+
+// regexMethod returns the method name of re bound to it, so that
+// `re.match(s)` calls it with s.
+func regexMethod(re *object.Regex, name string) object.Object {
+	var method func(re *regexp.Regexp, args []object.Object) object.Object
+
+	switch name {
+	case "match":
+		method = regexMatch
+	case "find":
+		method = regexFind
+	case "find_all":
+		method = regexFindAll
+	case "captures":
+		method = regexCaptures
+	case "named_captures":
+		method = regexNamedCaptures
+	case "replace":
+		method = regexReplace
+	case "split":
+		method = regexSplit
+	default:
+		return newError("%s has no method %s", re.Type(), name)
+	}
+
+	// 引数は各メソッドが checkArgs で検査する
+	return &object.Builtin{Name: "regex." + name, Fn: func(args ...object.Object) object.Object {
+		return method(re.Regexp, args)
+	}}
+}
+
+// match(s) reports whether s contains a match.
+func regexMatch(re *regexp.Regexp, args []object.Object) object.Object {
+	if err := checkArgs("match", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(re.MatchString(args[0].(*object.String).Value))
+}
+
+// find(s) returns the leftmost match, or null.
+func regexFind(re *regexp.Regexp, args []object.Object) object.Object {
+	if err := checkArgs("find", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	loc := re.FindStringIndex(args[0].(*object.String).Value)
+	if loc == nil {
+		return NULL
+	}
+	return &object.String{Value: args[0].(*object.String).Value[loc[0]:loc[1]]}
+}
+
+// find_all(s) returns every match, find_all(s, n) at most n of them.
+func regexFindAll(re *regexp.Regexp, args []object.Object) object.Object {
+	n, err := optionalCount("find_all", args)
+	if err != nil {
+		return err
+	}
+	return stringsToArray(re.FindAllString(args[0].(*object.String).Value, n))
+}
+
+// captures(s) returns the leftmost match followed by its submatches,
+// or null when there is no match.
+func regexCaptures(re *regexp.Regexp, args []object.Object) object.Object {
+	if err := checkArgs("captures", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	groups := re.FindStringSubmatch(args[0].(*object.String).Value)
+	if groups == nil {
+		return NULL
+	}
+	return stringsToArray(groups)
+}
+
+// named_captures(s) returns a hash of the named groups of the leftmost
+// match, or null when there is no match.
+func regexNamedCaptures(re *regexp.Regexp, args []object.Object) object.Object {
+	if err := checkArgs("named_captures", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+	groups := re.FindStringSubmatch(args[0].(*object.String).Value)
+	if groups == nil {
+		return NULL
+	}
+
+	pairs := make(map[object.HashKey]object.HashPair)
+	for i, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		key := &object.String{Value: name}
+		pairs[key.HashKey()] = object.HashPair{Key: key, Value: &object.String{Value: groups[i]}}
+	}
+	return &object.Hash{Pairs: pairs}
+}
+
+// replace(s, repl) replaces every match. A STRING repl may refer to groups
+// with $1 or ${name}; a function repl is called with each match and must
+// return a STRING.
+func regexReplace(re *regexp.Regexp, args []object.Object) object.Object {
+	if err := checkArgs("replace", args, object.STRING_OBJ, ""); err != nil {
+		return err
+	}
+	s := args[0].(*object.String).Value
+
+	switch repl := args[1].(type) {
+	case *object.String:
+		return &object.String{Value: re.ReplaceAllString(s, repl.Value)}
+	case *object.Function, *object.Builtin:
+		var failure object.Object
+		result := re.ReplaceAllStringFunc(s, func(match string) string {
+			if failure != nil {
+				return match
+			}
+			replaced := applyFunction(repl, []object.Object{&object.String{Value: match}}, nil)
+			str, ok := replaced.(*object.String)
+			if !ok {
+				if isError(replaced) {
+					failure = replaced
+				} else {
+					failure = newError("replacement function must return STRING, got %s", replaced.Type())
+				}
+				return match
+			}
+			return str.Value
+		})
+		if failure != nil {
+			return failure
+		}
+		return &object.String{Value: result}
+	default:
+		return newError("argument 2 to `replace` must be STRING or FUNCTION, got %s", repl.Type())
+	}
+}
+
+// split(s) splits s around every match, split(s, n) into at most n parts.
+func regexSplit(re *regexp.Regexp, args []object.Object) object.Object {
+	n, err := optionalCount("split", args)
+	if err != nil {
+		return err
+	}
+	return stringsToArray(re.Split(args[0].(*object.String).Value, n))
+}
+
+// optionalCount validates (STRING) or (STRING, INTEGER) arguments and
+// returns the count, -1 meaning unlimited.
+func optionalCount(name string, args []object.Object) (int, *object.Error) {
+	if len(args) == 0 || len(args) > 2 {
+		return 0, newError("wrong number of arguments to `%s`. got=%d, want=1 or 2", name, len(args))
+	}
+	if len(args) == 2 {
+		if err := checkArgs(name, args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+			return 0, err
+		}
+		return int(args[1].(*object.Integer).Value), nil
+	}
+	if err := checkArgs(name, args, object.STRING_OBJ); err != nil {
+		return 0, err
+	}
+	return -1, nil
+}
+
+func stringsToArray(strs []string) *object.Array {
+	elements := make([]object.Object, len(strs))
+	for i, s := range strs {
+		elements[i] = &object.String{Value: s}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`regex("a+b").match("xaab")`, true},
+		{`regex("^a+b$").match("xaab")`, false},
+		{`regex("\d+").find("abc 123 456")`, "123"},
+		{`regex("\d+").find("abc")`, nil},
+		{`len(regex("\d+").find_all("1 22 333"))`, 3},
+		{`regex("\d+").find_all("1 22 333")[2]`, "333"},
+		{`len(regex("\d+").find_all("1 22 333", 2))`, 2},
+		{`regex("(\w+)@(\w+)").captures("mail: bob@example")[2]`, "example"},
+		{`regex("(\w+)@(\w+)").captures("nothing")`, nil},
+		{`regex("(?P<user>\w+)@(?P<host>\w+)").named_captures("bob@example")["user"]`, "bob"},
+		{`regex("(\w+)@(\w+)").replace("bob@example", "$2 at $1")`, "example at bob"},
+		{`regex("\d").replace("a1b2", fn(m) { m + m })`, "a11b22"},
+		{`len(regex(",\s*").split("a, b,c"))`, 3},
+		{`regex(",\s*").split("a, b,c")[1]`, "b"},
+		{`let re = regex("o"); let f = re.match; f("foo")`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	evaluated := testEval(`[regex("ab*"), regex("ab*")]`)
+	arr, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+	if arr.Elements[0].(*object.Regex).Regexp != arr.Elements[1].(*object.Regex).Regexp {
+		t.Errorf("same pattern was compiled twice")
+	}
+}
+
+func TestRegexCacheLimit(t *testing.T) {
+	first, err := compileRegex("a+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2*maxCachedRegexes; i++ {
+		if _, err := compileRegex(fmt.Sprintf("b{%d}", i)); err != nil {
+			t.Fatal(err)
+		}
+		// 使われ続けるパターンは追い出されない
+		if i == maxCachedRegexes/2 {
+			if re, _ := compileRegex("a+"); re != first {
+				t.Errorf("pattern in use was evicted")
+			}
+		}
+	}
+
+	regexCache.Lock()
+	size := len(regexCache.patterns)
+	regexCache.Unlock()
+	if size != maxCachedRegexes {
+		t.Errorf("wrong cache size. got=%d, want=%d", size, maxCachedRegexes)
+	}
+	if re, _ := compileRegex("a+"); re == first {
+		t.Errorf("least recently used pattern was not evicted")
+	}
+}
+
+func TestRegexErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`regex("(")`, "invalid regex: error parsing regexp: missing closing ): `(`"},
+		{`regex("a").nope`, "REGEX has no method nope"},
+		{`regex("a").match(1)`, "argument 1 to `match` must be STRING, got INTEGER"},
+		{`regex("a").match()`, "wrong number of arguments to `match`. got=0, want=1"},
+		{`regex("a").find("a", "b")`, "wrong number of arguments to `find`. got=2, want=1"},
+		{`regex("a").replace("a")`, "wrong number of arguments to `replace`. got=1, want=2"},
+		{`regex("a").split()`, "wrong number of arguments to `split`. got=0, want=1 or 2"},
+		{`regex("a").find_all("a", 1, 2)`, "wrong number of arguments to `find_all`. got=3, want=1 or 2"},
+		{`regex("a").split("a", "b")`, "argument 2 to `split` must be INTEGER, got STRING"},
+		{`regex("a").replace("a", fn(m) { 1 })`, "replacement function must return STRING, got INTEGER"},
+		{`regex("a").replace("a", fn(m) { m - 1 })`, "type mismatch: STRING - INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
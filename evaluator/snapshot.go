@@ -0,0 +1,261 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/printer"
+	"github.com/Bo0km4n/dummy-monkey/resolver"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// SnapshotVersion is the version of the format written by Snapshot.
+const SnapshotVersion = 1
+
+// A snapshot is a JSON document holding the variables of an environment:
+//
+//	{"version": 1, "bindings": [
+//	  {"name": "x", "value": {"type": "INTEGER", "value": 1}},
+//	  {"name": "xs", "value": {"type": "ARRAY", "elements": [...]}},
+//	  {"name": "h", "value": {"type": "HASH", "pairs": [{"key": ..., "value": ...}]}},
+//	  {"name": "f", "value": {"type": "FUNCTION", "source": "fn(a) { a }"}}
+//	]}
+type snapshot struct {
+	Version  int               `json:"version"`
+	Bindings []snapshotBinding `json:"bindings"`
+}
+
+type snapshotBinding struct {
+	Name  string         `json:"name"`
+	Value *snapshotValue `json:"value"`
+}
+
+type snapshotValue struct {
+	Type     object.ObjectType `json:"type"`
+	Value    json.RawMessage   `json:"value,omitempty"`    // INTEGER, FLOAT, BOOLEAN, STRING
+	Elements []*snapshotValue  `json:"elements,omitempty"` // ARRAY
+	Pairs    []snapshotPair    `json:"pairs,omitempty"`    // HASH
+	Source   string            `json:"source,omitempty"`   // FUNCTION
+}
+
+type snapshotPair struct {
+	Key   *snapshotValue `json:"key"`
+	Value *snapshotValue `json:"value"`
+}
+
+// Snapshot encodes the variables bound by name in env. Integers, floats,
+// booleans, null, strings, arrays, hashes and functions defined in env are
+// saved; functions are saved as their source and so must not refer to
+// local variables of other functions. The bindings that cannot be saved
+// are left out and listed in skipped with the reason.
+func Snapshot(env *object.Environment) (data []byte, skipped []string) {
+	s := snapshot{Version: SnapshotVersion, Bindings: []snapshotBinding{}}
+	for _, name := range env.Names() {
+		val, _ := env.Get(name)
+		v, err := encodeValue(val, env)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		s.Bindings = append(s.Bindings, snapshotBinding{Name: name, Value: v})
+	}
+
+	data, _ = json.MarshalIndent(s, "", "  ")
+	return append(data, '\n'), skipped
+}
+
+func encodeValue(obj object.Object, env *object.Environment) (*snapshotValue, error) {
+	v := &snapshotValue{Type: obj.Type()}
+	switch obj := obj.(type) {
+	case *object.NULL:
+	case *object.Integer, *object.Boolean:
+		v.Value = json.RawMessage(obj.Inspect())
+	case *object.Float:
+		if math.IsInf(obj.Value, 0) || math.IsNaN(obj.Value) {
+			return nil, fmt.Errorf("cannot save %s", obj.Inspect())
+		}
+		v.Value, _ = json.Marshal(obj.Value)
+	case *object.String:
+		v.Value, _ = json.Marshal(obj.Value)
+	case *object.Array:
+		v.Elements = []*snapshotValue{}
+		for _, el := range obj.Elements {
+			e, err := encodeValue(el, env)
+			if err != nil {
+				return nil, err
+			}
+			v.Elements = append(v.Elements, e)
+		}
+	case *object.Hash:
+		for _, pair := range obj.Pairs {
+			key, err := encodeValue(pair.Key, env)
+			if err != nil {
+				return nil, err
+			}
+			value, err := encodeValue(pair.Value, env)
+			if err != nil {
+				return nil, err
+			}
+			v.Pairs = append(v.Pairs, snapshotPair{Key: key, Value: value})
+		}
+		// 出力を毎回同じにするためキーの順に並べる
+		sort.Slice(v.Pairs, func(i, j int) bool {
+			return string(v.Pairs[i].Key.Value) < string(v.Pairs[j].Key.Value)
+		})
+	case *object.Function:
+		if obj.Env != env {
+			return nil, fmt.Errorf("cannot save a closure over local variables")
+		}
+		v.Source = functionSource(obj)
+	default:
+		return nil, fmt.Errorf("cannot save %s", obj.Type())
+	}
+	return v, nil
+}
+
+// functionSource prints fn as a function literal that parses back to it.
+func functionSource(fn *object.Function) string {
+	literal := &ast.FunctionLiteral{
+		Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+		Parameters: fn.Parameters,
+		Defaults:   fn.Defaults,
+		Rest:       fn.Rest,
+		Body:       fn.Body,
+	}
+	program := &ast.Program{Statements: []ast.Statement{
+		&ast.ExpressionStatement{Token: literal.Token, Expression: literal},
+	}}
+
+	var out bytes.Buffer
+	printer.Fprint(&out, program, nil)
+	// 式文に付く ; と改行は含めない
+	return strings.TrimSuffix(out.String(), ";\n")
+}
+
+// Restore binds the variables saved by Snapshot in env. Functions are
+// defined again from their source; nothing else is evaluated.
+func Restore(data []byte, env *object.Environment) error {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid snapshot: %s", err)
+	}
+	if s.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+
+	// 関数は互いに参照するかもしれないので, 全ての名前を宣言済みとして解決する
+	names := BuiltinNames()
+	names = append(names, env.Names()...)
+	for _, b := range s.Bindings {
+		names = append(names, b.Name)
+	}
+
+	values := make([]object.Object, len(s.Bindings))
+	for i, b := range s.Bindings {
+		val, err := decodeValue(b.Value, env, names)
+		if err != nil {
+			return fmt.Errorf("%s: %s", b.Name, err)
+		}
+		if fn, ok := val.(*object.Function); ok {
+			fn.Name = b.Name
+		}
+		values[i] = val
+	}
+	// 途中で失敗したら env を変えない
+	for i, b := range s.Bindings {
+		env.Set(b.Name, values[i])
+	}
+	return nil
+}
+
+func decodeValue(v *snapshotValue, env *object.Environment, names []string) (object.Object, error) {
+	if v == nil {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	switch v.Type {
+	case object.NULL_OBJ:
+		return NULL, nil
+	case object.INTEGER_OBJ:
+		var i int64
+		err := json.Unmarshal(v.Value, &i)
+		return &object.Integer{Value: i}, err
+	case object.FLOAT_OBJ:
+		var f float64
+		err := json.Unmarshal(v.Value, &f)
+		return &object.Float{Value: f}, err
+	case object.BOOLEAN_OBJ:
+		var b bool
+		err := json.Unmarshal(v.Value, &b)
+		return nativeBoolToBooleanObject(b), err
+	case object.STRING_OBJ:
+		var s string
+		err := json.Unmarshal(v.Value, &s)
+		return &object.String{Value: s}, err
+	case object.ARRAY_OBJ:
+		elements := []object.Object{}
+		for _, el := range v.Elements {
+			obj, err := decodeValue(el, env, names)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, obj)
+		}
+		return &object.Array{Elements: elements}, nil
+	case object.HASH_OBJ:
+		pairs := map[object.HashKey]object.HashPair{}
+		for _, p := range v.Pairs {
+			key, err := decodeValue(p.Key, env, names)
+			if err != nil {
+				return nil, err
+			}
+			hashKey, ok := key.(object.Hashable)
+			if !ok {
+				return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+			}
+			value, err := decodeValue(p.Value, env, names)
+			if err != nil {
+				return nil, err
+			}
+			pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+		}
+		return &object.Hash{Pairs: pairs}, nil
+	case object.FUNCTION_OBJ:
+		return decodeFunction(v.Source, env, names)
+	}
+	return nil, fmt.Errorf("cannot restore %s", v.Type)
+}
+
+// decodeFunction defines the function literal in source in env.
+func decodeFunction(source string, env *object.Environment, names []string) (object.Object, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("invalid function %q: %s", source, p.Errors()[0])
+	}
+	if len(program.Statements) != 1 {
+		return nil, fmt.Errorf("invalid function %q", source)
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, fmt.Errorf("invalid function %q", source)
+	}
+	if _, ok := stmt.Expression.(*ast.FunctionLiteral); !ok {
+		return nil, fmt.Errorf("invalid function %q", source)
+	}
+
+	r := resolver.New(names...)
+	r.Resolve(program)
+	if len(r.Errors()) != 0 {
+		return nil, fmt.Errorf("invalid function %q: %s", source, r.Errors()[0])
+	}
+	return Eval(stmt.Expression, env), nil
+}
@@ -0,0 +1,139 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func evalInEnv(t *testing.T, input string, env *object.Environment) object.Object {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors: %v", p.Errors())
+	}
+	return Eval(program, env)
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	env := object.NewEnvironment()
+	evalInEnv(t, `
+let i = -3;
+let f = 2.5;
+let b = false;
+let n = if (false) { 1 };
+let s = "a \"quoted\"\nline";
+let xs = [1, [2, "three"], []];
+let h = {"a": 1, 2: [true], false: {}};
+let square = fn(x) { x * x };
+let twice = fn(g, x = 1, ...rest) { g(g(x)) };
+let apply = fn() { twice(square, i) };
+let step = fn(v) { v };
+let count = fn(x, n) { for (x; x < n; step(x)) { ++x } x };
+`, env)
+
+	data, skipped := Snapshot(env)
+	if len(skipped) != 0 {
+		t.Fatalf("unexpected skipped bindings: %v", skipped)
+	}
+
+	restored := object.NewEnvironment()
+	if err := Restore(data, restored); err != nil {
+		t.Fatalf("Restore failed: %s\n%s", err, data)
+	}
+
+	for _, name := range env.Names() {
+		want, _ := env.Get(name)
+		got, ok := restored.Get(name)
+		if !ok {
+			t.Errorf("%s not restored", name)
+			continue
+		}
+		if got.Type() != want.Type() || got.Inspect() != want.Inspect() && want.Type() != object.HASH_OBJ {
+			t.Errorf("%s: wrong value. got=%s, want=%s", name, got.Inspect(), want.Inspect())
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"apply()", "81"},
+		{"h[2][0]", "true"},
+		{"h[false]", "{}"},
+		{"xs[1][1]", "three"},
+		{"s", "a \"quoted\"\nline"},
+		{"twice(fn(x) { x + 1 })", "3"},
+		{"count(0, 3)", "3"},
+	}
+	for _, tt := range tests {
+		if got := evalInEnv(t, tt.input, restored).Inspect(); got != tt.expected {
+			t.Errorf("%s: got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSnapshotSkipsUnsupportedValues(t *testing.T) {
+	env := object.NewEnvironment()
+	evalInEnv(t, `
+let x = 1;
+let p = puts;
+let counter = fn(n) { fn() { n } }(1);
+let wrapped = [len];
+`, env)
+
+	data, skipped := Snapshot(env)
+	expected := []string{
+		"counter: cannot save a closure over local variables",
+		"p: cannot save BUILTIN",
+		"wrapped: cannot save BUILTIN",
+	}
+	if strings.Join(skipped, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong skipped bindings.\ngot:  %q\nwant: %q", skipped, expected)
+	}
+
+	restored := object.NewEnvironment()
+	if err := Restore(data, restored); err != nil {
+		t.Fatal(err)
+	}
+	if names := restored.Names(); len(names) != 1 || names[0] != "x" {
+		t.Errorf("wrong bindings restored. got=%v", names)
+	}
+}
+
+func TestRestoreErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`not json`, "invalid snapshot: "},
+		{`{"version": 99, "bindings": []}`, "unsupported snapshot version 99"},
+		{`{"version": 1, "bindings": [{"name": "x", "value": {"type": "REGEX"}}]}`, "x: cannot restore REGEX"},
+		{`{"version": 1, "bindings": [{"name": "x"}]}`, "x: missing value"},
+		{`{"version": 1, "bindings": [{"name": "f", "value": {"type": "FUNCTION", "source": "1 + 2"}}]}`,
+			`f: invalid function "1 + 2"`},
+		{`{"version": 1, "bindings": [{"name": "f", "value": {"type": "FUNCTION", "source": "fn() { y }"}}]}`,
+			`f: invalid function "fn() { y }": undefined variable: y`},
+		{`{"version": 1, "bindings": [{"name": "h", "value": {"type": "HASH", "pairs": [{"key": {"type": "ARRAY"}, "value": {"type": "NULL"}}]}}]}`,
+			"h: unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("keep", &object.Integer{Value: 1})
+		err := Restore([]byte(tt.input), env)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.input)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("%s: wrong error. got=%q, want prefix %q", tt.input, err, tt.expected)
+		}
+		if names := env.Names(); len(names) != 1 {
+			t.Errorf("%s: environment changed by failed restore: %v", tt.input, names)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package evaluator
+
+import (
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+// tailCall is returned instead of calling a function in tail position, so
+// that applyFunction can run the call in its loop. It never escapes the
+// evaluator.
+type tailCall struct {
+	fn   *object.Function
+	args []object.Object
+	node *ast.CallExpression
+}
+
+func (tc *tailCall) Type() object.ObjectType { return "TAIL_CALL" }
+func (tc *tailCall) Inspect() string         { return "tail call " + tc.node.String() }
+
+// evalTail evaluates node in tail position: the last expression of a
+// function body, a branch of an if in tail position, or a return value.
+func evalTail(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		if Coverage != nil {
+			Coverage.Statement(node)
+		}
+		return evalTail(node.Expression, env)
+	case *ast.IfExpression:
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		coverBranch(node, isTruthy(condition))
+		if isTruthy(condition) {
+			return evalTailBlock(node.Consequence, env)
+		} else if node.Alternative != nil {
+			return evalTailBlock(node.Alternative, env)
+		}
+		return NULL
+	case *ast.CallExpression:
+		return evalCallExpression(node, env, true)
+	default:
+		return Eval(node, env)
+	}
+}
+
+// evalTailBlock is evalBlockStatement with its last statement in tail position.
+func evalTailBlock(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for i, statement := range block.Statements {
+		if i == len(block.Statements)-1 {
+			return evalTail(statement, env)
+		}
+
+		result = Eval(statement, env)
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// forceTailCall runs a tail call that reached the top level of a program.
+func forceTailCall(obj object.Object) object.Object {
+	if call, ok := obj.(*tailCall); ok {
+		result := applyFunction(call.fn, call.args, call.node)
+		if err, ok := result.(*object.Error); ok {
+			err.Stack = append(err.Stack, callFrame(call.fn, call.node))
+		}
+		return result
+	}
+	return obj
+}
+
+// maxTailFrames is the number of the latest tail calls of a chain kept
+// for stack traces. Older ones are counted but not kept, so that deep tail
+// recursion runs in constant memory.
+const maxTailFrames = 64
+
+// tailFrames records the frames of the tail calls run by one
+// applyFunction loop.
+type tailFrames struct {
+	frames  []object.Frame
+	omitted int
+}
+
+func (t *tailFrames) push(frame object.Frame) {
+	t.frames = append(t.frames, frame)
+	// 溢れたら古い方を捨てる. 2 倍まで溜めてから詰めるので償却 O(1)
+	if len(t.frames) == 2*maxTailFrames {
+		t.omitted += maxTailFrames
+		t.frames = append(t.frames[:0], t.frames[maxTailFrames:]...)
+	}
+}
+
+// attach adds the tail calls to the stack of obj if it is an error, the
+// latest first and a frame counting the omitted ones last.
+func (t *tailFrames) attach(obj object.Object) object.Object {
+	err, ok := obj.(*object.Error)
+	if !ok || len(t.frames) == 0 {
+		return obj
+	}
+	omitted := t.omitted
+	frames := t.frames
+	if len(frames) > maxTailFrames {
+		omitted += len(frames) - maxTailFrames
+		frames = frames[len(frames)-maxTailFrames:]
+	}
+	for i := len(frames) - 1; i >= 0; i-- {
+		err.Stack = append(err.Stack, frames[i])
+	}
+	if omitted > 0 {
+		err.Stack = append(err.Stack, object.Frame{Omitted: omitted})
+	}
+	return obj
+}
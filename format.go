@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/printer"
+)
+
+// formatCommand implements `monkey fmt [-w] [-l] [-check] [path ...]`.
+// Without paths it formats standard input. Directories are searched for
+// .monkey files. It returns the exit status: 1 when -check finds a file
+// that is not formatted, 2 when a file cannot be read or parsed.
+func formatCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write result to the source file instead of standard output")
+	list := flags.Bool("l", false, "list files whose formatting differs")
+	check := flags.Bool("check", false, "list files whose formatting differs and exit with status 1 if there are any")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		src, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		out, err := printer.Format(src)
+		if err != nil {
+			fmt.Fprintf(stderr, "<stdin>: %s\n", err)
+			return 2
+		}
+		if *check {
+			if !bytes.Equal(src, out) {
+				fmt.Fprintln(stdout, "<stdin>")
+				return 1
+			}
+			return 0
+		}
+		stdout.Write(out)
+		return 0
+	}
+
+	files, err := monkeyFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	status := 0
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 2
+			continue
+		}
+		out, err := printer.Format(src)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", file, err)
+			status = 2
+			continue
+		}
+
+		changed := !bytes.Equal(src, out)
+		if changed && (*list || *check) {
+			fmt.Fprintln(stdout, file)
+		}
+		switch {
+		case *check:
+			if changed && status == 0 {
+				status = 1
+			}
+		case *write:
+			if changed {
+				if err := ioutil.WriteFile(file, out, 0644); err != nil {
+					fmt.Fprintln(stderr, err)
+					status = 2
+				}
+			}
+		case !*list:
+			stdout.Write(out)
+		}
+	}
+	return status
+}
+
+// monkeyFiles expands directories in paths to the Monkey files they contain.
+func monkeyFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && filepath.Ext(file) == evaluator.ModuleExt {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
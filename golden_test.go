@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/repl"
+)
+
+var update = flag.Bool("update", false, "rewrite the .out files of TestGolden")
+
+// TestGolden runs every script under testdata and compares what it
+// writes, its final value and its errors with the .out file next to it.
+// After adding a script or changing its output on purpose, regenerate
+// the golden files with `go test -run TestGolden -update`.
+func TestGolden(t *testing.T) {
+	files := []string{}
+	err := filepath.Walk("testdata", func(file string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Ext(file) == evaluator.ModuleExt {
+			files = append(files, file)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.ToSlash(file), func(t *testing.T) {
+			got := runGolden(t, file)
+			golden := strings.TrimSuffix(file, evaluator.ModuleExt) + ".out"
+			if *update {
+				if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%s (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s.\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// runGolden runs the script file and returns its output with absolute
+// paths made relative to the working directory.
+func runGolden(t *testing.T, file string) string {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// testdata/modules/vendor のモジュールはどのスクリプトからも import できる
+	defer func(old []string) { evaluator.SearchPath = old }(evaluator.SearchPath)
+	evaluator.SearchPath = []string{filepath.Join("testdata", "modules", "vendor")}
+	repl.Optimize = false
+	repl.Echo = false
+
+	var out bytes.Buffer
+	repl.Execute(src, file, nil, &out, &out)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Replace(out.String(), wd+string(filepath.Separator), "", -1)
+}
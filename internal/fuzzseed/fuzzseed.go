@@ -0,0 +1,32 @@
+// Package fuzzseed builds the seed corpus shared by the fuzz tests of the
+// lexer, the parser and the evaluator.
+package fuzzseed
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Add adds the scripts under ../testdata, seen from the package being
+// tested, and extra to the corpus of f.
+func Add(f *testing.F, extra ...string) {
+	err := filepath.Walk("../testdata", func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(file) != ".monkey" {
+			return err
+		}
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		f.Add(string(src))
+		return nil
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, s := range extra {
+		f.Add(s)
+	}
+}
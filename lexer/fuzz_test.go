@@ -0,0 +1,22 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/internal/fuzzseed"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+func FuzzNextToken(f *testing.F) {
+	fuzzseed.Add(f, `"abc`, `"a\`, "#!", "1.2.3", "0x", "/* a", "a // b")
+	f.Fuzz(func(t *testing.T, input string) {
+		l := New(input)
+		// どのトークンも 1 文字以上読むので, 入力の長さ + EOF より多くはならない
+		for i := 0; i <= len(input); i++ {
+			if l.NextToken().Type == token.EOF {
+				return
+			}
+		}
+		t.Fatalf("no EOF after %d tokens of %q", len(input)+1, input)
+	})
+}
@@ -1,6 +1,8 @@
 package lexer
 
 import (
+	"strings"
+
 	"github.com/Bo0km4n/dummy-monkey/token"
 )
 
@@ -9,17 +11,32 @@ type Lexer struct {
 	position     int  // 入力における現在の位置
 	readPosition int  // これから読み込む位置
 	ch           byte // 現在検査中の文字
+	line         int  // 現在の文字の行番号
+	column       int  // 現在の文字の列番号
+
+	comments []token.Token // 読み飛ばしたコメント
 }
 
 func New(input string) *Lexer {
 	l := &Lexer{
 		input: input,
+		line:  1,
 	}
 	l.readChar()
+	// 実行可能なスクリプトの #! 行はコメントとして読み飛ばす
+	if strings.HasPrefix(input, "#!") {
+		l.comments = append(l.comments, l.readComment())
+	}
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	// 入力が終端に到達したかのチェック
 	// 終端に到達した場合NULL文字にする
 	if l.readPosition >= len(l.input) {
@@ -40,10 +57,18 @@ func (l *Lexer) peekChar() byte {
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	line, column := l.line, l.column
+	tok := l.nextToken()
+	tok.Line = line
+	tok.Column = column
+	return tok
+}
+
+func (l *Lexer) nextToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
@@ -119,6 +144,15 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RBRACKET, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
+	case '.':
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+			tok.Type = token.ELLIPSIS
+			tok.Literal = "..."
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '%':
 		tok = newToken(token.PERCENT, l.ch)
 	default:
@@ -139,6 +173,13 @@ func (l *Lexer) NextToken() token.Token {
 			// 10進数
 			tok.Type = token.INT
 			tok.Literal = l.readNumber()
+
+			// 小数
+			if l.ch == '.' && isDigit(l.peekChar()) {
+				l.readChar()
+				tok.Type = token.FLOAT
+				tok.Literal += "." + l.readNumber()
+			}
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
@@ -158,7 +199,8 @@ func newToken(tokenType token.TokenType, ch byte) token.Token {
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	// 先頭以外は数字も使える
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
@@ -188,19 +230,61 @@ func isLetter(ch byte) bool {
 	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
 }
 
+// skipWhitespace skips whitespace and `//` comments. Comments are kept so
+// that tools like the formatter can put them back.
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+	for {
+		for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+			l.readChar()
+		}
+		if l.ch != '/' || l.peekChar() != '/' {
+			return
+		}
+		l.comments = append(l.comments, l.readComment())
+	}
+}
+
+func (l *Lexer) readComment() token.Token {
+	tok := token.Token{Type: token.COMMENT, Line: l.line, Column: l.column}
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
 	}
+	tok.Literal = strings.TrimRight(l.input[position:l.position], " \t\r")
+	return tok
+}
+
+// Comments returns the comments skipped so far, in source order.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
 }
 
 func (l *Lexer) readString() string {
-	position := l.position + 1
+	var out []byte
 	for {
 		l.readChar()
 		if l.ch == '"' || l.ch == 0 {
 			break
 		}
+		// エスケープシーケンス. l.ch は行番号の計算に使うので書き換えない
+		c := l.ch
+		if l.ch == '\\' {
+			switch l.peekChar() {
+			case '"', '\\':
+				l.readChar()
+				c = l.ch
+			case 'n':
+				l.readChar()
+				c = '\n'
+			case 't':
+				l.readChar()
+				c = '\t'
+			case 'r':
+				l.readChar()
+				c = '\r'
+			}
+		}
+		out = append(out, c)
 	}
-	return l.input[position:l.position]
+	return string(out)
 }
@@ -34,6 +34,11 @@ func TestNextToken(t *testing.T) {
 		break;
 	}
 	true && false
+	import "lib.monkey" as lib;
+	export let x = lib.add;
+	3.14;
+	"a\"b\\c\n"
+	f(...rest);
 	`
 	tests := []struct {
 		expectedType    token.TokenType
@@ -148,6 +153,28 @@ func TestNextToken(t *testing.T) {
 		{token.TRUE, "true"},
 		{token.DOUBLE_AND, "&&"},
 		{token.FALSE, "false"},
+		{token.IMPORT, "import"},
+		{token.STRING, "lib.monkey"},
+		{token.IDENT, "as"},
+		{token.IDENT, "lib"},
+		{token.SEMICOLON, ";"},
+		{token.EXPORT, "export"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "lib"},
+		{token.DOT, "."},
+		{token.IDENT, "add"},
+		{token.SEMICOLON, ";"},
+		{token.FLOAT, "3.14"},
+		{token.SEMICOLON, ";"},
+		{token.STRING, "a\"b\\c\n"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -167,3 +194,131 @@ func TestNextToken(t *testing.T) {
 		t.Log(tok)
 	}
 }
+
+func TestIdentifierWithDigits(t *testing.T) {
+	expected := []token.Token{
+		{Type: token.IDENT, Literal: "vec2"},
+		{Type: token.IDENT, Literal: "a1b2"},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l := New("vec2 a1b2 2;")
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.Type || tok.Literal != want.Literal {
+			t.Errorf("tokens[%d] wrong. expected=%s %q, got=%s %q", i, want.Type, want.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPosition(t *testing.T) {
+	input := "let x = 5;\n  x +\n\t\"str\";\n\"a\\nb\" + y"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"x", 2, 3},
+		{"+", 2, 5},
+		{"str", 3, 2},
+		{";", 3, 7},
+		// エスケープされた改行は行を進めない
+		{"a\nb", 4, 1},
+		{"+", 4, 8},
+		{"y", 4, 10},
+		{"", 4, 11},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "// header\nlet x = 10 / 2; // half  \n// end"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	expected := []token.Token{
+		{Type: token.COMMENT, Literal: "// header", Line: 1, Column: 1},
+		{Type: token.COMMENT, Literal: "// half", Line: 2, Column: 17},
+		{Type: token.COMMENT, Literal: "// end", Line: 3, Column: 1},
+	}
+	comments := l.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("wrong number of comments. expected=%d, got=%d (%v)", len(expected), len(comments), comments)
+	}
+	for i, c := range expected {
+		if comments[i] != c {
+			t.Errorf("comments[%d] wrong. expected=%+v, got=%+v", i, c, comments[i])
+		}
+	}
+}
+
+func TestShebang(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected token.Token
+		comments int
+	}{
+		{"#!/usr/bin/env monkey\nlet x", token.Token{Type: token.LET, Literal: "let", Line: 2, Column: 1}, 1},
+		{"#!/usr/bin/env monkey", token.Token{Type: token.EOF, Literal: "", Line: 1, Column: 22}, 1},
+		{"let x", token.Token{Type: token.LET, Literal: "let", Line: 1, Column: 1}, 0},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok != tt.expected {
+			t.Errorf("%q: wrong first token. expected=%+v, got=%+v", tt.input, tt.expected, tok)
+		}
+		if len(l.Comments()) != tt.comments {
+			t.Errorf("%q: wrong number of comments. expected=%d, got=%d", tt.input, tt.comments, len(l.Comments()))
+		}
+	}
+
+	// 2行目以降の #! は読み飛ばさない
+	l := New("1\n#!")
+	l.NextToken()
+	if tok := l.NextToken(); tok.Type != token.ILLEGAL {
+		t.Errorf("expected ILLEGAL for #! after the first line, got=%+v", tok)
+	}
+}
@@ -0,0 +1,396 @@
+// Package lineedit reads lines from a terminal with Emacs style editing:
+// cursor movement, a history that can be browsed and searched, and tab
+// completion. When the input is not a terminal, keys are read as they
+// come without switching the terminal to raw mode.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+type Editor struct {
+	in  *bufio.Reader
+	out io.Writer
+	fd  int // raw モードにする端末. 端末でなければ -1
+
+	History *History
+
+	// Complete returns the candidates for word, the identifier before the
+	// cursor. Candidates that do not start with word are ignored.
+	Complete func(word string) []string
+}
+
+// New returns an editor reading keys from in and echoing to out.
+func New(in io.Reader, out io.Writer) *Editor {
+	e := &Editor{
+		in:      bufio.NewReader(in),
+		out:     out,
+		fd:      -1,
+		History: NewHistory(),
+	}
+	if f, ok := in.(*os.File); ok && IsTerminal(int(f.Fd())) {
+		e.fd = int(f.Fd())
+	}
+	return e
+}
+
+// ReadLine shows prompt and returns the line typed by the user. It
+// returns io.EOF when the input ends or Ctrl-D is pressed on an empty
+// line, and ErrInterrupted on Ctrl-C. Lines are not added to the history;
+// the caller decides which ones to keep.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.fd >= 0 {
+		restore, err := makeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	s := &state{e: e, prompt: prompt, hist: e.History.Len()}
+	s.refresh()
+	return s.read()
+}
+
+func ctrl(r rune) rune {
+	return r & 0x1f
+}
+
+const (
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// state は編集中の行
+type state struct {
+	e      *Editor
+	prompt string
+	buf    []rune
+	pos    int
+
+	hist  int    // 表示している履歴の位置. History.Len() なら入力中の行
+	saved []rune // 履歴を遡る前に入力していた行
+}
+
+func (s *state) write(text string) {
+	io.WriteString(s.e.out, text)
+}
+
+// refresh redraws the prompt and the line and moves the cursor to pos.
+func (s *state) refresh() {
+	text := "\r" + s.prompt + string(s.buf) + "\x1b[K"
+	if n := len(s.buf) - s.pos; n > 0 {
+		text += fmt.Sprintf("\x1b[%dD", n)
+	}
+	s.write(text)
+}
+
+func (s *state) read() (string, error) {
+	for {
+		r, _, err := s.e.in.ReadRune()
+		if err != nil {
+			// 改行のない最後の行
+			if err == io.EOF && len(s.buf) > 0 {
+				s.write("\r\n")
+				return string(s.buf), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			s.write("\r\n")
+			return string(s.buf), nil
+		case ctrl('C'):
+			s.write("^C\r\n")
+			return "", ErrInterrupted
+		case ctrl('D'):
+			if len(s.buf) == 0 {
+				s.write("\r\n")
+				return "", io.EOF
+			}
+			s.delete()
+		case ctrl('A'):
+			s.pos = 0
+		case ctrl('E'):
+			s.pos = len(s.buf)
+		case ctrl('B'):
+			s.left()
+		case ctrl('F'):
+			s.right()
+		case ctrl('H'), keyBackspace:
+			s.backspace()
+		case ctrl('K'):
+			s.buf = s.buf[:s.pos]
+		case ctrl('U'):
+			s.buf = append([]rune{}, s.buf[s.pos:]...)
+			s.pos = 0
+		case ctrl('W'):
+			start := s.wordStart(s.pos)
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+		case ctrl('L'):
+			s.write("\x1b[H\x1b[2J")
+		case ctrl('P'):
+			s.history(-1)
+		case ctrl('N'):
+			s.history(1)
+		case ctrl('R'):
+			if s.search() {
+				s.write("\r\n")
+				return string(s.buf), nil
+			}
+		case '\t':
+			s.complete()
+		case keyEscape:
+			s.escape()
+		default:
+			if unicode.IsPrint(r) {
+				s.insert([]rune{r})
+			}
+		}
+		s.refresh()
+	}
+}
+
+func (s *state) insert(text []rune) {
+	buf := append([]rune{}, s.buf[:s.pos]...)
+	buf = append(buf, text...)
+	s.buf = append(buf, s.buf[s.pos:]...)
+	s.pos += len(text)
+}
+
+func (s *state) delete() {
+	if s.pos < len(s.buf) {
+		s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+	}
+}
+
+func (s *state) backspace() {
+	if s.pos > 0 {
+		s.pos--
+		s.delete()
+	}
+}
+
+func (s *state) left() {
+	if s.pos > 0 {
+		s.pos--
+	}
+}
+
+func (s *state) right() {
+	if s.pos < len(s.buf) {
+		s.pos++
+	}
+}
+
+// wordStart returns the start of the word before pos, skipping spaces.
+func (s *state) wordStart(pos int) int {
+	for pos > 0 && unicode.IsSpace(s.buf[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(s.buf[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordEnd returns the end of the word after pos, skipping spaces.
+func (s *state) wordEnd(pos int) int {
+	for pos < len(s.buf) && unicode.IsSpace(s.buf[pos]) {
+		pos++
+	}
+	for pos < len(s.buf) && !unicode.IsSpace(s.buf[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// escape handles the escape sequences sent by arrow and editing keys, and
+// Alt-b and Alt-f.
+func (s *state) escape() {
+	r, _, err := s.e.in.ReadRune()
+	if err != nil {
+		return
+	}
+	switch r {
+	case 'b':
+		s.pos = s.wordStart(s.pos)
+		return
+	case 'f':
+		s.pos = s.wordEnd(s.pos)
+		return
+	case '[', 'O':
+	default:
+		return
+	}
+
+	// ESC [ <引数> <終端文字>
+	seq := ""
+	for {
+		r, _, err := s.e.in.ReadRune()
+		if err != nil {
+			return
+		}
+		seq += string(r)
+		if r >= '@' && r <= '~' {
+			break
+		}
+	}
+
+	switch seq {
+	case "A":
+		s.history(-1)
+	case "B":
+		s.history(1)
+	case "C":
+		s.right()
+	case "D":
+		s.left()
+	case "H", "1~", "7~":
+		s.pos = 0
+	case "F", "4~", "8~":
+		s.pos = len(s.buf)
+	case "3~":
+		s.delete()
+	}
+}
+
+// history replaces the line with the previous (dir < 0) or next entry.
+func (s *state) history(dir int) {
+	h := s.e.History
+	next := s.hist + dir
+	if next < 0 || next > h.Len() {
+		return
+	}
+	if s.hist == h.Len() {
+		s.saved = s.buf
+	}
+	s.hist = next
+	if next == h.Len() {
+		s.buf = s.saved
+	} else {
+		s.buf = []rune(h.At(next))
+	}
+	s.pos = len(s.buf)
+}
+
+// search runs a reverse incremental search of the history. It reports
+// whether the line was accepted with Enter; any other key that is not
+// part of the search leaves the match in the line and is handled as usual.
+func (s *state) search() bool {
+	h := s.e.History
+	query := ""
+	match := -1
+
+	for {
+		found := ""
+		if match >= 0 {
+			found = h.At(match)
+		}
+		s.write(fmt.Sprintf("\r(reverse-i-search)`%s': %s\x1b[K", query, found))
+
+		r, _, err := s.e.in.ReadRune()
+		if err != nil {
+			return false
+		}
+		switch {
+		case r == ctrl('R'):
+			if match >= 0 {
+				if i := h.search(query, match); i >= 0 {
+					match = i
+				}
+			}
+		case r == ctrl('H') || r == keyBackspace:
+			if len(query) > 0 {
+				query = string([]rune(query)[:len([]rune(query))-1])
+				match = h.search(query, h.Len())
+			}
+		case r == ctrl('G') || r == ctrl('C'):
+			return false
+		case r == '\r' || r == '\n':
+			if match >= 0 {
+				s.buf = []rune(found)
+			}
+			return true
+		case unicode.IsPrint(r):
+			query += string(r)
+			from := h.Len()
+			if match >= 0 {
+				from = match + 1
+			}
+			match = h.search(query, from)
+		default:
+			if match >= 0 {
+				s.buf = []rune(found)
+				s.pos = len(s.buf)
+			}
+			s.e.in.UnreadRune()
+			return false
+		}
+	}
+}
+
+// complete completes the word before the cursor. A unique candidate is
+// inserted; otherwise the common prefix of the candidates is inserted, or
+// the candidates are listed if there is none.
+func (s *state) complete() {
+	if s.e.Complete == nil {
+		return
+	}
+	start := s.pos
+	for start > 0 && isWordChar(s.buf[start-1]) {
+		start--
+	}
+	// 行頭の :command も補完する
+	if start == 1 && s.buf[0] == ':' {
+		start = 0
+	}
+	word := string(s.buf[start:s.pos])
+
+	seen := map[string]bool{}
+	candidates := []string{}
+	for _, c := range s.e.Complete(word) {
+		if strings.HasPrefix(c, word) && !seen[c] {
+			seen[c] = true
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Strings(candidates)
+
+	switch len(candidates) {
+	case 0:
+		s.write("\a")
+		return
+	case 1:
+		s.insert([]rune(candidates[0][len(word):]))
+		return
+	}
+
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) > len(word) {
+		s.insert([]rune(prefix[len(word):]))
+		return
+	}
+	s.write("\r\n" + strings.Join(candidates, "  ") + "\r\n")
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,167 @@
+package lineedit
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newEditor(keys string, history ...string) (*Editor, *bytes.Buffer) {
+	var out bytes.Buffer
+	e := New(strings.NewReader(keys), &out)
+	for _, line := range history {
+		e.History.Add(line)
+	}
+	return e, &out
+}
+
+func TestReadLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string
+		expected string
+	}{
+		{"plain", "let x = 1;\r", "let x = 1;"},
+		{"backspace", "lett\x7f x\r", "let x"},
+		{"ctrl-h", "ab\x08c\r", "ac"},
+		{"left and insert", "ac\x1b[Db\r", "abc"},
+		{"ctrl-b and ctrl-f", "ac\x02\x02\x06b\r", "abc"},
+		{"home and end", "bc\x1b[Ha\x1b[Fd\r", "abcd"},
+		{"ctrl-a and ctrl-e", "bc\x01a\x05d\r", "abcd"},
+		{"delete", "abc\x01\x1b[3~\r", "bc"},
+		{"ctrl-d deletes", "abc\x01\x04\r", "bc"},
+		{"ctrl-k", "abcdef\x02\x02\x02\x0b\r", "abc"},
+		{"ctrl-u", "abcdef\x02\x02\x02\x15\r", "def"},
+		{"ctrl-w", "let x = foo\x17bar\r", "let x = bar"},
+		{"alt-b and alt-f", "one three\x1bb\x1bbtwo \x1bf\x1bf!\r", "two one three!"},
+		{"ignores control keys", "a\x1b[5~\x00b\r", "ab"},
+		{"unicode", "\"日本\"\x1b[D\x1b[D語\r", "\"日語本\""},
+		{"line without newline", "abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		e, _ := newEditor(tt.keys)
+		line, err := e.ReadLine(">> ")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%s: wrong line. got=%q, want=%q", tt.name, line, tt.expected)
+		}
+	}
+}
+
+func TestReadLineEOFAndInterrupt(t *testing.T) {
+	e, _ := newEditor("")
+	if _, err := e.ReadLine(">> "); err != io.EOF {
+		t.Errorf("expected io.EOF at end of input. got=%v", err)
+	}
+
+	e, _ = newEditor("\x04")
+	if _, err := e.ReadLine(">> "); err != io.EOF {
+		t.Errorf("expected io.EOF on Ctrl-D. got=%v", err)
+	}
+
+	e, _ = newEditor("abc\x03def\r")
+	if _, err := e.ReadLine(">> "); err != ErrInterrupted {
+		t.Errorf("expected ErrInterrupted on Ctrl-C. got=%v", err)
+	}
+	if line, _ := e.ReadLine(">> "); line != "def" {
+		t.Errorf("wrong line after Ctrl-C. got=%q, want=%q", line, "def")
+	}
+}
+
+func TestReadLineRedraw(t *testing.T) {
+	e, out := newEditor("ab\x1b[D\r")
+	e.ReadLine(">> ")
+
+	expected := "\r>> \x1b[K" +
+		"\r>> a\x1b[K" +
+		"\r>> ab\x1b[K" +
+		"\r>> ab\x1b[K\x1b[1D" +
+		"\r\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. got=%q, want=%q", out.String(), expected)
+	}
+}
+
+func TestHistoryKeys(t *testing.T) {
+	history := []string{"first", "second", "third"}
+	tests := []struct {
+		name     string
+		keys     string
+		expected string
+	}{
+		{"up", "\x1b[A\r", "third"},
+		{"up twice", "\x1b[A\x1b[A\r", "second"},
+		{"past the oldest", "\x1b[A\x1b[A\x1b[A\x1b[A\r", "first"},
+		{"ctrl-p and ctrl-n", "\x10\x10\x0e\r", "third"},
+		{"back to the typed line", "abc\x1b[A\x1b[A\x1b[B\x1b[B\r", "abc"},
+		{"edit an entry", "\x1b[A!\r", "third!"},
+	}
+
+	for _, tt := range tests {
+		e, _ := newEditor(tt.keys, history...)
+		line, _ := e.ReadLine(">> ")
+		if line != tt.expected {
+			t.Errorf("%s: wrong line. got=%q, want=%q", tt.name, line, tt.expected)
+		}
+	}
+}
+
+func TestReverseSearch(t *testing.T) {
+	history := []string{"let add = fn(a, b) { a + b };", "puts(1)", "add(1, 2)", "puts(2)"}
+	tests := []struct {
+		name     string
+		keys     string
+		expected string
+	}{
+		{"enter accepts", "\x12add\r", "add(1, 2)"},
+		{"ctrl-r finds older", "\x12add\x12\r", "let add = fn(a, b) { a + b };"},
+		{"narrowing keeps match", "\x12pu\x12ts\r", "puts(1)"},
+		{"backspace", "\x12addx\x7f\r", "add(1, 2)"},
+		{"other key edits the match", "\x12puts\x05;\r", "puts(2);"},
+		{"arrow key leaves search", "\x12puts\x1b[D\x1b[D!\r", "puts(!2)"},
+		{"ctrl-g cancels", "abc\x12puts\x07\r", "abc"},
+		{"no match", "\x12zzz\r", ""},
+	}
+
+	for _, tt := range tests {
+		e, _ := newEditor(tt.keys, history...)
+		line, _ := e.ReadLine(">> ")
+		if line != tt.expected {
+			t.Errorf("%s: wrong line. got=%q, want=%q", tt.name, line, tt.expected)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	words := []string{"let", "len", "last", "length", "puts", ":help", ":env"}
+	tests := []struct {
+		name     string
+		keys     string
+		expected string
+		listed   string
+	}{
+		{"unique", "pu\t(1)\r", "puts(1)", ""},
+		{"common prefix", "len\t\r", "len", "len  length"},
+		{"extends to common prefix", "x = le\tn\r", "x = len", "len  length  let"},
+		{"in the middle", "(l)\x1b[Da\t\r", "(last)", ""},
+		{"command", ":h\t\r", ":help", ""},
+		{"no candidates", "zz\t\r", "zz", ""},
+	}
+
+	for _, tt := range tests {
+		e, out := newEditor(tt.keys)
+		e.Complete = func(word string) []string { return words }
+		line, _ := e.ReadLine(">> ")
+		if line != tt.expected {
+			t.Errorf("%s: wrong line. got=%q, want=%q", tt.name, line, tt.expected)
+		}
+		if tt.listed != "" && !strings.Contains(out.String(), "\r\n"+tt.listed+"\r\n") {
+			t.Errorf("%s: candidates %q not listed. got=%q", tt.name, tt.listed, out.String())
+		}
+	}
+}
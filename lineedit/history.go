@@ -0,0 +1,121 @@
+package lineedit
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// MaxHistory is the number of entries a History keeps.
+const MaxHistory = 1000
+
+// History is the list of lines entered so far, oldest first. If it has a
+// file, added lines are appended to it as they are entered.
+type History struct {
+	entries []string
+	file    string
+}
+
+// NewHistory returns an empty history that is not saved.
+func NewHistory() *History {
+	return &History{}
+}
+
+// LoadHistory reads the history saved in file, one entry per line, and
+// appends new entries to it. A missing file is not an error.
+func LoadHistory(file string) (*History, error) {
+	h := &History{file: file}
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+
+	err = h.Read(f)
+	if len(h.entries) >= MaxHistory {
+		// 長くなりすぎたファイルは読み込んだ分だけに書き直す
+		h.rewrite()
+	}
+	return h, err
+}
+
+// Read appends the lines of r to the history.
+func (h *History) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		h.add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Write writes the entries to w, one per line.
+func (h *History) Write(w io.Writer) error {
+	for _, entry := range h.entries {
+		if _, err := io.WriteString(w, entry+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add appends line to the history, unless it is blank or repeats the
+// last entry, and to the history file.
+func (h *History) Add(line string) {
+	if !h.add(line) || h.file == "" {
+		return
+	}
+	f, err := os.OpenFile(h.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	io.WriteString(f, line+"\n")
+}
+
+func (h *History) add(line string) bool {
+	if strings.TrimSpace(line) == "" || strings.Contains(line, "\n") {
+		return false
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return false
+	}
+	h.entries = append(h.entries, line)
+	if len(h.entries) > MaxHistory {
+		h.entries = h.entries[len(h.entries)-MaxHistory:]
+	}
+	return true
+}
+
+func (h *History) rewrite() {
+	f, err := os.OpenFile(h.file, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	h.Write(f)
+}
+
+// Len returns the number of entries.
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// At returns the i-th entry, oldest first.
+func (h *History) At(i int) string {
+	return h.entries[i]
+}
+
+// search returns the index of the newest entry before from that contains
+// query, or -1.
+func (h *History) search(query string, from int) int {
+	for i := from - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
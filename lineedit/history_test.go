@@ -0,0 +1,80 @@
+package lineedit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	h := NewHistory()
+	for _, line := range []string{"a", "", "  ", "b", "b", "a", "x\ny"} {
+		h.Add(line)
+	}
+
+	var out strings.Builder
+	h.Write(&out)
+	if out.String() != "a\nb\na\n" {
+		t.Errorf("wrong entries. got=%q, want=%q", out.String(), "a\nb\na\n")
+	}
+}
+
+func TestHistoryMax(t *testing.T) {
+	h := NewHistory()
+	for i := 0; i < MaxHistory+10; i++ {
+		h.Add(strings.Repeat("x", i+1))
+	}
+	if h.Len() != MaxHistory {
+		t.Fatalf("wrong length. got=%d, want=%d", h.Len(), MaxHistory)
+	}
+	if len(h.At(0)) != 11 {
+		t.Errorf("oldest entries not dropped. got=%q", h.At(0))
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+
+	h, err := LoadHistory(file)
+	if err != nil {
+		t.Fatalf("LoadHistory on a missing file: %v", err)
+	}
+	h.Add("let x = 1;")
+	h.Add("x + 1")
+
+	h, err = LoadHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 2 || h.At(0) != "let x = 1;" || h.At(1) != "x + 1" {
+		t.Errorf("history not restored. got %d entries", h.Len())
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("wrong permissions. got=%v", info.Mode().Perm())
+	}
+}
+
+func TestHistoryFileIsTrimmed(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	var lines strings.Builder
+	for i := 0; i < MaxHistory+5; i++ {
+		lines.WriteString(strings.Repeat("x", i+1) + "\n")
+	}
+	if err := os.WriteFile(file, []byte(lines.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadHistory(file); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(file)
+	if n := strings.Count(string(data), "\n"); n != MaxHistory {
+		t.Errorf("history file not trimmed. got %d lines, want %d", n, MaxHistory)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package lineedit
+
+import "errors"
+
+// IsTerminal reports whether fd refers to a terminal. Raw mode is only
+// supported on Linux and macOS; elsewhere input is read line by line.
+func IsTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/linter"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+// lintCommand implements `monkey lint [-json] [-enable rules] [-disable rules] [path ...]`.
+// Without paths it lints standard input. It returns 1 when problems were
+// found and 2 when a file cannot be read or parsed.
+func lintCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the problems as a JSON array")
+	enable := flags.String("enable", "", "comma separated rules to run, instead of all of them")
+	disable := flags.String("disable", "", "comma separated rules to skip")
+	listRules := flags.Bool("rules", false, "list the available rules and exit")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *listRules {
+		for _, r := range linter.Rules {
+			fmt.Fprintf(stdout, "%-20s %s\n", r.Name, r.Description)
+		}
+		return 0
+	}
+
+	l := linter.New()
+	if *enable != "" {
+		all := []string{}
+		for _, r := range linter.Rules {
+			all = append(all, r.Name)
+		}
+		l.Disable(all...)
+		if err := l.Enable(splitList(*enable)...); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+	if err := l.Disable(splitList(*disable)...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	files := []string{"-"}
+	if flags.NArg() > 0 {
+		var err error
+		if files, err = monkeyFiles(flags.Args()); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+
+	status := 0
+	diagnostics := []linter.Diagnostic{}
+	for _, file := range files {
+		src, _, err := readSource(file, stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 2
+			continue
+		}
+		if file == "-" {
+			file = "<stdin>"
+		}
+		p := parser.New(lexer.New(string(src)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			for _, msg := range p.Errors() {
+				fmt.Fprintf(stderr, "%s: %s\n", file, msg)
+			}
+			status = 2
+			continue
+		}
+
+		for _, d := range l.Lint(program) {
+			d.File = file
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(diagnostics, "", "  ")
+		fmt.Fprintln(stdout, string(out))
+	} else {
+		for _, d := range diagnostics {
+			fmt.Fprintln(stdout, d)
+		}
+	}
+
+	if status == 0 && len(diagnostics) > 0 {
+		status = 1
+	}
+	return status
+}
+
+func splitList(s string) []string {
+	names := []string{}
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
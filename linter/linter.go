@@ -0,0 +1,387 @@
+// Package linter reports suspicious constructs in Monkey programs: unused
+// variables and parameters, builtins hidden by declarations, unreachable
+// code, constant conditions, values that are overwritten before they are
+// read and switch cases without break. Every rule can be turned off
+// individually.
+package linter
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// Rule describes a check the linter can run.
+type Rule struct {
+	Name        string
+	Description string
+}
+
+// Rules lists every rule, in the order they are documented.
+var Rules = []Rule{
+	{"unused-variable", "let bindings that are never read"},
+	{"unused-parameter", "function parameters that are never read"},
+	{"shadowed-builtin", "declarations that hide a builtin function"},
+	{"unreachable-code", "statements after a return"},
+	{"constant-condition", "if and for conditions that are always true or always false"},
+	{"unused-assignment", "values that are overwritten before they are read"},
+	{"missing-break", "switch cases that do not end with break"},
+}
+
+// Diagnostic is a problem found by a rule.
+type Diagnostic struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	pos := fmt.Sprintf("%d:%d", d.Line, d.Column)
+	if d.File != "" {
+		pos = d.File + ":" + pos
+	}
+	return fmt.Sprintf("%s: %s (%s)", pos, d.Message, d.Rule)
+}
+
+// Linter runs the enabled rules over programs.
+type Linter struct {
+	enabled map[string]bool
+}
+
+// New returns a linter with every rule enabled.
+func New() *Linter {
+	l := &Linter{enabled: map[string]bool{}}
+	for _, r := range Rules {
+		l.enabled[r.Name] = true
+	}
+	return l
+}
+
+// Enable turns the named rules on.
+func (l *Linter) Enable(names ...string) error {
+	return l.set(names, true)
+}
+
+// Disable turns the named rules off.
+func (l *Linter) Disable(names ...string) error {
+	return l.set(names, false)
+}
+
+func (l *Linter) set(names []string, enabled bool) error {
+	for _, name := range names {
+		if _, ok := l.enabled[name]; !ok {
+			return fmt.Errorf("unknown rule: %s", name)
+		}
+		l.enabled[name] = enabled
+	}
+	return nil
+}
+
+// Lint checks program and returns the problems found, ordered by position.
+func (l *Linter) Lint(program *ast.Program) []Diagnostic {
+	c := &checker{
+		linter:   l,
+		builtins: map[string]bool{},
+	}
+	for _, name := range evaluator.BuiltinNames() {
+		c.builtins[name] = true
+	}
+
+	c.open()
+	c.block(program.Statements)
+	c.close()
+
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		a, b := c.diagnostics[i], c.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.diagnostics
+}
+
+type variable struct {
+	ident    *ast.Identifier
+	param    bool
+	exported bool
+	used     bool
+
+	// 最後の代入と, その後に読まれたか
+	write       *ast.Identifier
+	writeBlock  []ast.Statement
+	readSinceWr bool
+}
+
+type scope struct {
+	outer *scope
+	vars  map[string]*variable
+	// 宣言より前に現れた参照 (関数の中から後で宣言される変数を参照する場合)
+	pending []string
+}
+
+type checker struct {
+	linter      *Linter
+	builtins    map[string]bool
+	scope       *scope
+	diagnostics []Diagnostic
+
+	// 現在の文の列. unused-assignment は同じ列の中の代入だけを比べる
+	current []ast.Statement
+}
+
+func (c *checker) report(rule string, tok token.Token, format string, a ...interface{}) {
+	if !c.linter.enabled[rule] {
+		return
+	}
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+func (c *checker) open() {
+	c.scope = &scope{outer: c.scope, vars: map[string]*variable{}}
+}
+
+// close reports the unused variables of the innermost scope and hands
+// references it could not bind to the enclosing scope.
+func (c *checker) close() {
+	s := c.scope
+	for _, name := range s.pending {
+		if v, ok := s.vars[name]; ok {
+			v.used = true
+		} else if s.outer != nil {
+			s.outer.pending = append(s.outer.pending, name)
+		}
+	}
+
+	for name, v := range s.vars {
+		if v.used || v.exported || strings.HasPrefix(name, "_") {
+			continue
+		}
+		// トップレベルの test_ 関数は monkey test が呼び出す
+		if s.outer == nil && strings.HasPrefix(name, "test_") {
+			continue
+		}
+		if v.param {
+			c.report("unused-parameter", v.ident.Token, "parameter %s is never used", name)
+		} else {
+			c.report("unused-variable", v.ident.Token, "%s is declared but never used", name)
+		}
+	}
+	c.scope = s.outer
+}
+
+func (c *checker) declare(ident *ast.Identifier, param, exported bool) {
+	if c.builtins[ident.Value] {
+		c.report("shadowed-builtin", ident.Token, "%s shadows the builtin function %s", ident.Value, ident.Value)
+	}
+
+	v, ok := c.scope.vars[ident.Value]
+	if !ok {
+		v = &variable{ident: ident, param: param}
+		c.scope.vars[ident.Value] = v
+	} else if v.write != nil && !v.readSinceWr && sameBlock(v.writeBlock, c.current) {
+		c.report("unused-assignment", v.write.Token, "value assigned to %s is never read", ident.Value)
+	}
+	v.exported = v.exported || exported
+	v.write = ident
+	v.writeBlock = c.current
+	v.readSinceWr = false
+}
+
+func sameBlock(a, b []ast.Statement) bool {
+	return len(a) > 0 && len(b) > 0 && &a[0] == &b[0]
+}
+
+func (c *checker) use(ident *ast.Identifier) {
+	for s := c.scope; s != nil; s = s.outer {
+		if v, ok := s.vars[ident.Value]; ok {
+			v.used = true
+			v.readSinceWr = true
+			return
+		}
+	}
+	c.scope.pending = append(c.scope.pending, ident.Value)
+}
+
+// block checks a statement list of a program, block or case.
+func (c *checker) block(stmts []ast.Statement) {
+	outer := c.current
+	c.current = stmts
+	defer func() { c.current = outer }()
+
+	for i, stmt := range stmts {
+		c.walk(stmt)
+		if _, ok := stmt.(*ast.ReturnStatement); ok && i+1 < len(stmts) {
+			c.report("unreachable-code", statementToken(stmts[i+1]), "unreachable code")
+			// 到達しない文も変数の使用としては数える
+			for _, rest := range stmts[i+1:] {
+				c.walk(rest)
+			}
+			return
+		}
+	}
+}
+
+func (c *checker) walk(node ast.Node) {
+	ast.Inspect(node, c.visit)
+}
+
+func (c *checker) visit(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.BlockStatement:
+		c.block(n.Statements)
+		return false
+	case *ast.LetStatement:
+		c.walk(n.Value)
+		c.declare(n.Name, false, false)
+		return false
+	case *ast.ExportStatement:
+		c.walk(n.Statement.Value)
+		c.declare(n.Statement.Name, false, true)
+		return false
+	case *ast.ImportStatement:
+		c.importStatement(n)
+		return false
+	case *ast.DoublePlusStatement:
+		c.use(n.Name)
+		return false
+	case *ast.Identifier:
+		c.use(n)
+	case *ast.DotExpression:
+		// メンバー名は変数ではない
+		c.walk(n.Left)
+		return false
+	case *ast.FunctionLiteral:
+		c.function(n)
+		return false
+	case *ast.ForExpression:
+		c.open()
+		c.walk(n.InitStatement)
+		c.condition(n.Token, n.FinishCondition)
+		c.walk(n.FinishCondition)
+		c.walk(n.Consequence)
+		c.walk(n.LoopStatement)
+		c.close()
+		return false
+	case *ast.IfExpression:
+		c.condition(n.Token, n.Condition)
+	case *ast.SwitchStatement:
+		for _, cs := range n.Case {
+			if !cs.Break {
+				c.report("missing-break", cs.Token, "case does not end with break")
+			}
+		}
+		if n.Expression != nil {
+			c.walk(n.Expression)
+		}
+		for _, cs := range n.Case {
+			c.walk(cs.Condition)
+			c.block(cs.Statements)
+		}
+		return false
+	}
+	return true
+}
+
+func (c *checker) function(fn *ast.FunctionLiteral) {
+	if fn.Name != nil {
+		c.declare(fn.Name, false, false)
+		// 宣言した関数は呼ばれなくても問題にしない
+		c.scope.vars[fn.Name.Value].used = true
+	}
+
+	c.open()
+	outer := c.current
+	c.current = nil
+	for i, p := range fn.Parameters {
+		if i < len(fn.Defaults) && fn.Defaults[i] != nil {
+			c.walk(fn.Defaults[i])
+		}
+		c.declare(p, true, false)
+	}
+	if fn.Rest != nil {
+		c.declare(fn.Rest, true, false)
+	}
+	c.current = outer
+	c.walk(fn.Body)
+	c.close()
+}
+
+func (c *checker) importStatement(node *ast.ImportStatement) {
+	names := []*ast.Identifier{}
+	if node.Names != nil {
+		for _, n := range node.Names {
+			names = append(names, n.Binding())
+		}
+	} else if node.Alias != nil {
+		names = append(names, node.Alias)
+	} else {
+		base := filepath.Base(node.Path.Value)
+		name := strings.TrimSuffix(base, filepath.Ext(base))
+		names = append(names, &ast.Identifier{Token: node.Path.Token, Value: name})
+	}
+
+	// 使われない import は unused-variable の対象にしない
+	for _, ident := range names {
+		c.declare(ident, false, true)
+	}
+}
+
+// condition reports if and for conditions made only of literals.
+func (c *checker) condition(tok token.Token, cond ast.Expression) {
+	if !isConstant(cond) {
+		return
+	}
+	if b, ok := cond.(*ast.Boolean); ok {
+		c.report("constant-condition", tok, "condition is always %t", b.Value)
+		return
+	}
+	c.report("constant-condition", tok, "condition is constant: %s", cond.String())
+}
+
+func isConstant(exp ast.Expression) bool {
+	switch exp := exp.(type) {
+	case *ast.Boolean, *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral:
+		return true
+	case *ast.PrefixExpression:
+		return isConstant(exp.Right)
+	case *ast.InfixExpression:
+		return isConstant(exp.Left) && isConstant(exp.Right)
+	}
+	return false
+}
+
+// statementToken returns the first token of stmt.
+func statementToken(stmt ast.Statement) token.Token {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		return stmt.Token
+	case *ast.ReturnStatement:
+		return stmt.Token
+	case *ast.ExpressionStatement:
+		return stmt.Token
+	case *ast.BlockStatement:
+		return stmt.Token
+	case *ast.DoublePlusStatement:
+		return stmt.Token
+	case *ast.SwitchStatement:
+		return stmt.Token
+	case *ast.ImportStatement:
+		return stmt.Token
+	case *ast.ExportStatement:
+		return stmt.Token
+	}
+	return token.Token{}
+}
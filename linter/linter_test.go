@@ -0,0 +1,91 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors: %v", len(p.Errors()), p.Errors())
+	}
+	return program
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1;", []string{"1:5: x is declared but never used (unused-variable)"}},
+		{"let x = 1; puts(x);", []string{}},
+		{"let _tmp = 1;", []string{}},
+		{"export let x = 1;", []string{}},
+		{"let f = fn(a, b) { a }; f(1, 2);", []string{"1:15: parameter b is never used (unused-parameter)"}},
+		{"let f = fn(a, ...others) { a }; f(1);", []string{"1:18: parameter others is never used (unused-parameter)"}},
+		{"let len = 1; puts(len);", []string{"1:5: len shadows the builtin function len (shadowed-builtin)"}},
+		{"let f = fn(puts) { puts }; f(1);", []string{"1:12: puts shadows the builtin function puts (shadowed-builtin)"}},
+		{"let f = fn() { return 1; puts(2); }; f();", []string{"1:26: unreachable code (unreachable-code)"}},
+		{"if (true) { puts(1) }", []string{"1:1: condition is always true (constant-condition)"}},
+		{"if (1 < 2) { puts(1) }", []string{"1:1: condition is constant: (1 < 2) (constant-condition)"}},
+		{"for (let i = 0; false; ++i) { puts(i) }", []string{"1:1: condition is always false (constant-condition)"}},
+		{"let x = 1; let x = 2; puts(x);", []string{"1:5: value assigned to x is never read (unused-assignment)"}},
+		{"let x = 1; puts(x); let x = 2; puts(x);", []string{}},
+		{"let x = 1; if (x > 0) { let x = 2; } puts(x);", []string{}},
+		{"let x = 1; let f = fn() { x }; let x = 2; f();", []string{}},
+		{"switch { case true: puts(1) }", []string{"1:10: case does not end with break (missing-break)"}},
+		{"switch { case true: puts(1); break; }", []string{}},
+		{"let f = fn() { g() }; let g = fn() { 1 }; f();", []string{}},
+		{"fn helper() { 1 }", []string{}},
+		{"let test_add = fn() { assert_eq(1 + 1, 2) };", []string{}},
+		{"let f = fn() { let test_x = 1; }; f();", []string{"1:20: test_x is declared but never used (unused-variable)"}},
+		{"import \"strings\"; import { a } from \"lib\";", []string{}},
+		{"let m = {\"len\": 1}; m.len", []string{}},
+		{"let f = fn(n) { for (let i = 0; i < n; ++i) { puts(i) } }; f(3);", []string{}},
+	}
+
+	for _, tt := range tests {
+		diagnostics := New().Lint(parse(t, tt.input))
+		if len(diagnostics) != len(tt.expected) {
+			t.Errorf("%q: wrong number of problems. got=%v, want=%v", tt.input, diagnostics, tt.expected)
+			continue
+		}
+		for i, msg := range tt.expected {
+			if diagnostics[i].String() != msg {
+				t.Errorf("%q: wrong problem. got=%q, want=%q", tt.input, diagnostics[i].String(), msg)
+			}
+		}
+	}
+}
+
+func TestEnableDisable(t *testing.T) {
+	input := "let len = 1; let f = fn(a) { return 1; 2 };"
+
+	l := New()
+	if err := l.Disable("unused-variable", "shadowed-builtin"); err != nil {
+		t.Fatal(err)
+	}
+	rules := []string{}
+	for _, d := range l.Lint(parse(t, input)) {
+		rules = append(rules, d.Rule)
+	}
+	if len(rules) != 2 || rules[0] != "unused-parameter" || rules[1] != "unreachable-code" {
+		t.Errorf("wrong rules reported. got=%v", rules)
+	}
+
+	if err := l.Enable("shadowed-builtin"); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(l.Lint(parse(t, input))); got != 3 {
+		t.Errorf("wrong number of problems after Enable. got=%d, want=3", got)
+	}
+
+	if err := l.Disable("no-such-rule"); err == nil {
+		t.Errorf("expected an error for an unknown rule")
+	}
+}
@@ -3,35 +3,229 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/user"
+	"path/filepath"
 
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
 	"github.com/Bo0km4n/dummy-monkey/repl"
 )
 
-var (
-	f = flag.String("file", "", "input file")
-)
+// command is a subcommand such as `monkey run`. run returns the exit
+// status.
+type command struct {
+	name string
+	args string // usage に表示する引数の説明
+	help string
+	run  func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
 
+var commands []command
+
+// usage は commands を参照するので init で登録する
 func init() {
-	flag.Parse()
+	commands = []command{
+		{"run", "[-O] [-echo] [-v] [-dump-optimized] [-coverage file] file|- [args...]", "run a script", runCommand},
+		{"repl", "[-v]", "start an interactive session (the default)", replCommand},
+		{"eval", "[-O] -e source [args...]", "run source given on the command line", evalCommand},
+		{"fmt", "[-w] [-l] [-check] [path...]", "format source files", formatCommand},
+		{"lint", "[-json] [-enable rules] [-disable rules] [path ...]", "report suspicious code", lintCommand},
+		{"check", "path...", "report undefined variables and type errors", checkCommand},
+		{"test", "[-run regexp] [-v] [-coverage file] [path...]", "run the test_ functions of *_test.monkey files", testCommand},
+	}
 }
 
 func main() {
-	user, err := user.Current()
-	if err != nil {
-		panic(err)
+	os.Exit(monkey(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// monkey runs the command line args and returns the exit status.
+func monkey(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("monkey", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { usage(flags, stderr) }
+	path := flags.String("path", os.Getenv("MONKEYPATH"), "module search path (list separated by "+string(filepath.ListSeparator)+")")
+	showTokens := flags.Bool("tokens", false, "print the tokens of the input file (or standard input) instead of running it")
+	showAST := flags.Bool("ast", false, "print the AST of the input file (or standard input) instead of running it")
+	asJSON := flags.Bool("json", false, "print -tokens and -ast output as JSON")
+	// 以前からのフラグ. `monkey -file f` は `monkey run f` と同じ
+	file := flags.String("file", "", "input file for -tokens and -ast, or script to run")
+	optimize := flags.Bool("O", false, "optimize the program before running it (with -file)")
+	dumpOptimized := flags.Bool("dump-optimized", false, "print the optimized program instead of running it (with -file)")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if *path != "" {
+		evaluator.SearchPath = filepath.SplitList(*path)
 	}
 
-	if *f != "" {
-		file, err := os.Open(*f)
+	if *showTokens || *showAST {
+		// monkey -tokens file は run と同じく位置引数のファイルを読む
+		name := *file
+		if name == "" {
+			name = flags.Arg(0)
+		}
+		if name == "" {
+			name = "-"
+		}
+		src, _, err := readSource(name, stdin)
 		if err != nil {
-			panic(err)
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		status := 0
+		if *showTokens {
+			dumpTokens(string(src), *asJSON, stdout)
+		}
+		if *showAST {
+			status = dumpAST(string(src), *asJSON, stdout, stderr)
+		}
+		return status
+	}
+
+	if *file != "" {
+		runArgs := []string{}
+		if *optimize {
+			runArgs = append(runArgs, "-O")
+		}
+		if *dumpOptimized {
+			runArgs = append(runArgs, "-dump-optimized")
+		}
+		runArgs = append(runArgs, *file)
+		return runCommand(append(runArgs, flags.Args()...), stdin, stdout, stderr)
+	}
+
+	if flags.NArg() == 0 {
+		return replCommand(nil, stdin, stdout, stderr)
+	}
+	name := flags.Arg(0)
+	for _, c := range commands {
+		if c.name == name {
+			return c.run(flags.Args()[1:], stdin, stdout, stderr)
+		}
+	}
+	// monkey script.monkey args... (#!/usr/bin/env monkey で始まるスクリプト)
+	if _, err := os.Stat(name); err == nil || name == "-" {
+		return runCommand(flags.Args(), stdin, stdout, stderr)
+	}
+	fmt.Fprintf(stderr, "monkey: unknown command or file %q\n", name)
+	usage(flags, stderr)
+	return 2
+}
+
+func usage(flags *flag.FlagSet, w io.Writer) {
+	fmt.Fprintf(w, "usage: monkey [flags] [command] [arguments]\n       monkey [flags] file|- [args...]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-6s %s\n", c.name, c.help)
+		if c.args != "" {
+			fmt.Fprintf(w, "         monkey %s %s\n", c.name, c.args)
 		}
-		repl.FileExecute(file, os.Stdout)
+	}
+	fmt.Fprintf(w, "\nflags:\n")
+	flags.PrintDefaults()
+}
+
+// readSource reads the script path, or standard input if path is "-". It
+// also returns the file name to resolve imports against, which is empty
+// for standard input.
+func readSource(path string, stdin io.Reader) ([]byte, string, error) {
+	if path == "-" {
+		src, err := ioutil.ReadAll(stdin)
+		return src, "", err
+	}
+	src, err := ioutil.ReadFile(path)
+	return src, path, err
+}
+
+// runCommand implements `monkey run [-O] [-echo] [-v] [-dump-optimized] [-coverage file] file|- [args...]`.
+// The arguments after the file are passed to the script. It returns 1 on
+// a runtime error and 2 when the script cannot be read or parsed. With
+// -coverage the coverage summary is written to stderr.
+func runCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	optimize := flags.Bool("O", false, "optimize the program before running it")
+	dumpOptimized := flags.Bool("dump-optimized", false, "print the optimized program instead of running it")
+	echo := flags.Bool("echo", false, "print the source before the output")
+	verbose := flags.Bool("v", false, verboseUsage)
+	coverFile := flags.String("coverage", "", coverageUsage)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: monkey run [-O] [-echo] [-v] [-dump-optimized] [-coverage file] file|- [args...]")
+		return 2
+	}
+
+	src, file, err := readSource(flags.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *dumpOptimized {
+		return repl.DumpOptimized(src, stdout, stderr)
+	}
+	repl.Optimize = *optimize
+	repl.Echo = *echo
+	repl.Verbose = *verbose
+	if *coverFile == "" {
+		return repl.Execute(src, file, flags.Args()[1:], stdout, stderr)
+	}
+
+	stop := startCoverage(*coverFile, stderr, stderr)
+	status := repl.Execute(src, file, flags.Args()[1:], stdout, stderr)
+	if err := stop(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return status
+}
+
+// evalCommand implements `monkey eval [-O] -e source [args...]`.
+func evalCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	source := flags.String("e", "", "source to run")
+	optimize := flags.Bool("O", false, "optimize the program before running it")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *source == "" {
+		fmt.Fprintln(stderr, "usage: monkey eval [-O] -e source [args...]")
+		return 2
+	}
+
+	repl.Optimize = *optimize
+	repl.Echo = false
+	repl.Verbose = false
+	return repl.Execute([]byte(*source), "", flags.Args(), stdout, stderr)
+}
+
+const verboseUsage = "print warnings such as declarations that shadow a variable"
+
+// replCommand implements `monkey repl [-v]`.
+func replCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("repl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	verbose := flags.Bool("v", false, verboseUsage)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	repl.Verbose = *verbose
+
+	// ユーザー名が分からなくても起動する
+	if u, err := user.Current(); err == nil {
+		fmt.Fprintf(stdout, "Hello %s! This is the Monket programming language!\n", u.Username)
 	} else {
-		fmt.Printf("Hello %s! This is the Monket programming language!\n", user.Username)
-		fmt.Printf("Feel free to type in commands\n")
-		repl.Start(os.Stdin, os.Stdout)
+		fmt.Fprintf(stdout, "Hello! This is the Monket programming language!\n")
 	}
+	fmt.Fprintf(stdout, "Feel free to type in commands\n")
+	repl.Start(stdin, stdout)
+	return 0
 }
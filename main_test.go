@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+)
+
+// cli runs the monkey command with args and input and returns its exit
+// status and output.
+func cli(input string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := monkey(args, strings.NewReader(input), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func writeFile(t *testing.T, dir, name, src string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	ok := writeFile(t, dir, "ok.monkey", `puts(len(args)); args[0]`)
+	runtimeError := writeFile(t, dir, "error.monkey", `puts("before"); 1 + "a"`)
+	parseError := writeFile(t, dir, "parse.monkey", `let = 1;`)
+
+	tests := []struct {
+		args   []string
+		input  string
+		status int
+		stdout string
+		stderr string
+	}{
+		{[]string{"run", ok, "a", "b"}, "", 0, "2\na\n", ""},
+		{[]string{"run", "-", "x"}, `puts(args)`, 0, "[x]\nnull\n", ""},
+		{[]string{"run", "-echo", "-", "x"}, `args`, 0, "args\n(↑ input code)====================================(↓ output)\n[x]\n", ""},
+		{[]string{"run", runtimeError}, "", 1, "before\n", "ERROR: type mismatch: INTEGER + STRING\n"},
+		{[]string{"run", parseError}, "", 2, "", "\texpected next token to be IDENT, got = instead\n\tno prefix parse function for = found\n"},
+		{[]string{"run", filepath.Join(dir, "missing.monkey")}, "", 2, "", "open " + filepath.Join(dir, "missing.monkey") + ": no such file or directory\n"},
+		{[]string{"run"}, "", 2, "", "usage: monkey run [-O] [-echo] [-v] [-dump-optimized] [-coverage file] file|- [args...]\n"},
+		{[]string{"run", "-"}, "let x = 1; let f = fn(x) { x }; f(2)", 0, "2\n", ""},
+		{[]string{"run", "-v", "-"}, "let x = 1; let f = fn(x) { x }; f(2)", 0, "2\n",
+			"\twarning: declaration of x shadows variable declared at line 1, column 5 (line 1, column 23)\n"},
+		{[]string{"-file", ok, "z"}, "", 0, "1\nz\n", ""},
+		{[]string{"run", "-dump-optimized", "-"}, "1 + 2", 0, "3\n", ""},
+		{[]string{"eval", "-e", "args[1] + args[0]", "a", "b"}, "", 0, "ba\n", ""},
+		{[]string{"eval", "-e", "x"}, "", 2, "", "\tundefined variable: x (line 1, column 1)\n"},
+		{[]string{"eval"}, "", 2, "", "usage: monkey eval [-O] -e source [args...]\n"},
+	}
+
+	for _, tt := range tests {
+		status, stdout, stderr := cli(tt.input, tt.args...)
+		if status != tt.status || stdout != tt.stdout || stderr != tt.stderr {
+			t.Errorf("monkey %s:\ngot  %d %q %q\nwant %d %q %q", strings.Join(tt.args, " "),
+				status, stdout, stderr, tt.status, tt.stdout, tt.stderr)
+		}
+	}
+}
+
+func TestDumpFlags(t *testing.T) {
+	file := writeFile(t, t.TempDir(), "x.monkey", "x;")
+	tokens := "1:1\tIDENT\t\"x\"\n1:2\t;\t\";\"\n1:3\tEOF\t\"\"\n"
+	ast := "Program\n  Statements[0]: ExpressionStatement 1:1\n    Expression: Identifier 1:1\n      Value: \"x\"\n"
+
+	tests := []struct {
+		args   []string
+		input  string
+		stdout string
+	}{
+		{[]string{"-tokens", file}, "", tokens},
+		{[]string{"-tokens", "-file", file}, "", tokens},
+		{[]string{"-tokens"}, "x;", tokens},
+		{[]string{"-tokens", "-"}, "x;", tokens},
+		{[]string{"-ast", file}, "", ast},
+		{[]string{"-ast"}, "x;", ast},
+	}
+
+	for _, tt := range tests {
+		status, stdout, stderr := cli(tt.input, tt.args...)
+		if status != 0 || stdout != tt.stdout || stderr != "" {
+			t.Errorf("monkey %s:\ngot  %d %q %q\nwant 0 %q \"\"", strings.Join(tt.args, " "),
+				status, stdout, stderr, tt.stdout)
+		}
+	}
+
+	if status, stdout, stderr := cli("let = 1;", "-ast"); status != 2 || stdout != "" || stderr == "" {
+		t.Errorf("monkey -ast with a parse error: got %d %q %q, want status 2 and errors on stderr", status, stdout, stderr)
+	}
+}
+
+func TestLintCommand(t *testing.T) {
+	dir := t.TempDir()
+	clean := writeFile(t, dir, "clean.monkey", "let x = 1;\nputs(x);\n")
+	unused := writeFile(t, dir, "unused.monkey", "let x = 1;\n")
+
+	tests := []struct {
+		args   []string
+		input  string
+		status int
+		stdout string
+	}{
+		{[]string{"lint", clean}, "", 0, ""},
+		{[]string{"lint", unused}, "", 1, unused + ":1:5: x is declared but never used (unused-variable)\n"},
+		{[]string{"lint"}, "let x = 1;\n", 1, "<stdin>:1:5: x is declared but never used (unused-variable)\n"},
+		{[]string{"lint"}, "let x = 1;\nputs(x);\n", 0, ""},
+	}
+
+	for _, tt := range tests {
+		status, stdout, stderr := cli(tt.input, tt.args...)
+		if status != tt.status || stdout != tt.stdout || stderr != "" {
+			t.Errorf("monkey %s:\ngot  %d %q %q\nwant %d %q \"\"", strings.Join(tt.args, " "),
+				status, stdout, stderr, tt.status, tt.stdout)
+		}
+	}
+
+	if status, _, stderr := cli("let = 1;", "lint"); status != 2 || !strings.HasPrefix(stderr, "<stdin>: ") {
+		t.Errorf("monkey lint with a parse error: got %d %q", status, stderr)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	status, _, stderr := cli("", "nope")
+	if status != 2 || !strings.HasPrefix(stderr, "monkey: unknown command or file \"nope\"\nusage:") {
+		t.Errorf("wrong result: %d %q", status, stderr)
+	}
+}
+
+func TestReplCommand(t *testing.T) {
+	status, stdout, _ := cli("1 + 2\n", "repl")
+	if status != 0 || !strings.Contains(stdout, ">> 3\n") {
+		t.Errorf("wrong result: %d %q", status, stdout)
+	}
+}
+
+func TestTestCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "math_test.monkey", `let add = fn(a, b) { a + b };
+let test_add = fn() { assert_eq(add(1, 2), 3) };
+let test_add_strings = fn() { assert_eq(add("1", "2"), 12, "concatenation") };
+fn test_error() { assert_error(fn() { add(1, "a") }, "type mismatch") }
+let helper_not_a_test = fn() { assert(false) };
+`)
+	writeFile(t, dir, "broken_test.monkey", `let x = ;`)
+	writeFile(t, dir, "other.monkey", `let test_ignored = fn() { assert(false) };`)
+
+	broken := filepath.Join(dir, "broken_test.monkey")
+	math := filepath.Join(dir, "math_test.monkey")
+	tests := []struct {
+		args     []string
+		status   int
+		expected string
+	}{
+		{[]string{"test", math}, 1,
+			"--- FAIL: test_add_strings (0.00s)\n" +
+				"\tERROR: assertion failed: concatenation: expected 12 (INTEGER), got 12 (STRING)\n" +
+				"\t    at assert_eq (line 3, column 40)\n" +
+				"FAIL\t" + math + "\t2 passed, 1 failed\n" +
+				"FAIL: 2 passed, 1 failed\n"},
+		{[]string{"test", "-run", "add$|error", math}, 0,
+			"ok\t" + math + "\t2 passed\nok: 2 passed\n"},
+		{[]string{"test", "-v", "-run", "^test_add$", math}, 0,
+			"=== RUN   test_add\n--- PASS: test_add (0.00s)\nok\t" + math + "\t1 passed\nok: 1 passed\n"},
+		{[]string{"test", "-run", "^none$", dir}, 1,
+			"\tno prefix parse function for ; found\nFAIL\t" + broken + "\n" +
+				"ok\t" + math + "\t0 passed\nFAIL: 0 passed, 0 failed\n"},
+		{[]string{"test", "-run", "("}, 2, ""},
+	}
+
+	for _, tt := range tests {
+		status, stdout, _ := cli("", tt.args...)
+		if status != tt.status || stdout != tt.expected {
+			t.Errorf("monkey %s: wrong result: %d\ngot:\n%s\nwant:\n%s", strings.Join(tt.args, " "),
+				status, stdout, tt.expected)
+		}
+	}
+}
+
+func TestRunScriptByPath(t *testing.T) {
+	dir := t.TempDir()
+	script := writeFile(t, dir, "hello", "#!/usr/bin/env monkey\nputs(\"hello \" + args[0]);\nexit(3);\nputs(\"not reached\")")
+
+	defer func(old func(int)) { evaluator.Exit = old }(evaluator.Exit)
+	evaluator.Exit = func(code int) { panic(code) }
+
+	var stdout, stderr bytes.Buffer
+	status := func() (status int) {
+		defer func() { status = recover().(int) }()
+		monkey([]string{script, "world"}, strings.NewReader(""), &stdout, &stderr)
+		return -1
+	}()
+	if status != 3 || stdout.String() != "hello world\n" {
+		t.Errorf("wrong result: %d %q %q", status, stdout.String(), stderr.String())
+	}
+}
+
+func TestCoverageFlag(t *testing.T) {
+	dir := t.TempDir()
+	script := writeFile(t, dir, "abs.monkey", "let abs = fn(n) {\n  if (n < 0) {\n    -n\n  } else {\n    n\n  }\n};\nabs(-2)\n")
+	lcov := filepath.Join(dir, "coverage.info")
+
+	status, stdout, stderr := cli("", "run", "-coverage", lcov, script)
+	if status != 0 || stdout != "2\n" {
+		t.Fatalf("wrong result: %d %q %q", status, stdout, stderr)
+	}
+	summary := script + "  4/5 statements (80.0%)  1/2 branches (50.0%)\n" +
+		"total" + strings.Repeat(" ", len(script)-3) + "4/5 statements (80.0%)  1/2 branches (50.0%)\n"
+	if stderr != summary {
+		t.Errorf("wrong summary.\ngot:\n%s\nwant:\n%s", stderr, summary)
+	}
+	report, err := ioutil.ReadFile(lcov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(report), "TN:\nSF:"+script+"\nDA:1,1\nDA:2,1\nDA:3,1\nDA:5,0\n") {
+		t.Errorf("wrong lcov report:\n%s", report)
+	}
+
+	// -O で畳み込まれる到達しないコードも実行されなかったと報告される
+	dead := writeFile(t, dir, "dead.monkey", "if (false) {\n  puts(1)\n}\nputs(2)\n")
+	status, stdout, stderr = cli("", "run", "-O", "-coverage", lcov, dead)
+	summary = dead + "  1/3 statements (33.3%)  0/2 branches (0.0%)\n" +
+		"total" + strings.Repeat(" ", len(dead)-3) + "1/3 statements (33.3%)  0/2 branches (0.0%)\n"
+	if status != 0 || stdout != "2\nnull\n" || stderr != summary {
+		t.Errorf("wrong result with -O: %d %q\ngot:\n%s\nwant:\n%s", status, stdout, stderr, summary)
+	}
+
+	test := writeFile(t, dir, "abs_test.monkey", "let test_abs = fn() { assert_eq(abs(-1), 1) };\nlet abs = fn(n) { if (n < 0) { -n } else { n } };\n")
+	html := filepath.Join(dir, "coverage.html")
+	status, stdout, _ = cli("", "test", "--coverage", html, test)
+	if status != 0 || !strings.HasPrefix(stdout, "ok\t"+test+"\t1 passed\nok: 1 passed\n"+test+"  ") {
+		t.Errorf("wrong result: %d %q", status, stdout)
+	}
+	report, err = ioutil.ReadFile(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), `<span class="line partial"><span class="number">2</span>`) {
+		t.Errorf("line 2 is not partially covered in the HTML report:\n%s", report)
+	}
+	if evaluator.Coverage != nil {
+		t.Errorf("coverage still recorded after the command")
+	}
+}
@@ -1,8 +1,12 @@
 package object
 
+import "sort"
+
 type Environment struct {
 	store map[string]Object
+	slots []Object // resolver で解決された変数
 	outer *Environment
+	file  string
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
@@ -12,6 +16,17 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 		outer: outer,
 	}
 }
+
+// NewFrame returns an environment enclosed by outer with size slots for
+// variables bound by the resolver. Names that were not resolved are still
+// stored by name.
+func NewFrame(outer *Environment, size int) *Environment {
+	return &Environment{
+		slots: make([]Object, size),
+		outer: outer,
+	}
+}
+
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
 	return &Environment{
@@ -28,6 +43,64 @@ func (e *Environment) Get(name string) (Object, bool) {
 }
 
 func (e *Environment) Set(name string, val Object) Object {
+	if e.store == nil {
+		e.store = make(map[string]Object)
+	}
 	e.store[name] = val
 	return val
 }
+
+// SetFile records the source file evaluated in this environment, so that
+// imports can be resolved relative to it.
+func (e *Environment) SetFile(path string) {
+	e.file = path
+}
+
+// File returns the source file of the nearest enclosing environment that
+// has one, or "" when evaluating code that did not come from a file.
+func (e *Environment) File() string {
+	if e.file == "" && e.outer != nil {
+		return e.outer.File()
+	}
+	return e.file
+}
+
+// GetAt returns the variable in slot of the environment depth levels out.
+func (e *Environment) GetAt(depth, slot int) (Object, bool) {
+	env := e.ancestor(depth)
+	if env == nil || slot >= len(env.slots) || env.slots[slot] == nil {
+		return nil, false
+	}
+	return env.slots[slot], true
+}
+
+// SetAt sets the variable in slot of the environment depth levels out.
+func (e *Environment) SetAt(depth, slot int, val Object) Object {
+	env := e.ancestor(depth)
+	if slot >= len(env.slots) {
+		slots := make([]Object, slot+1)
+		copy(slots, env.slots)
+		env.slots = slots
+	}
+	env.slots[slot] = val
+	return val
+}
+
+func (e *Environment) ancestor(depth int) *Environment {
+	env := e
+	for i := 0; i < depth && env != nil; i++ {
+		env = env.outer
+	}
+	return env
+}
+
+// Names returns the names bound by name in this environment, excluding
+// outer environments and resolved slots.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
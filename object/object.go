@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/Bo0km4n/dummy-monkey/ast"
@@ -13,6 +15,7 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -22,6 +25,8 @@ const (
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
 	HASH_OBJ         = "HASH"
+	MODULE_OBJ       = "MODULE"
+	REGEX_OBJ        = "REGEX"
 )
 
 type Object interface {
@@ -41,6 +46,22 @@ func (i *Integer) Type() ObjectType {
 	return INTEGER_OBJ
 }
 
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -77,6 +98,24 @@ func (rv *ReturnValue) Inspect() string {
 
 type Error struct {
 	Message string
+	Stack   []Frame // 呼び出しスタック. 最も内側の呼び出しが先頭
+}
+
+// Frame is a Monkey function call that an error propagated through. A
+// frame with Omitted set stands for that many tail calls left out of a
+// long chain.
+type Frame struct {
+	Function string
+	Line     int
+	Column   int
+	Omitted  int
+}
+
+func (f Frame) String() string {
+	if f.Omitted > 0 {
+		return fmt.Sprintf("... %d more tail calls", f.Omitted)
+	}
+	return fmt.Sprintf("at %s (line %d, column %d)", f.Function, f.Line, f.Column)
 }
 
 func (e *Error) Type() ObjectType {
@@ -87,10 +126,26 @@ func (e *Error) Inspect() string {
 	return "ERROR: " + e.Message
 }
 
+// Traceback returns Inspect followed by one line per stack frame.
+func (e *Error) Traceback() string {
+	var out bytes.Buffer
+
+	out.WriteString(e.Inspect())
+	for _, f := range e.Stack {
+		out.WriteString("\n    " + f.String())
+	}
+
+	return out.String()
+}
+
 type Function struct {
+	Name       string // 束縛された名前. 無名関数は ""
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Slots      int // 呼び出し時に確保する環境の大きさ
 }
 
 func (f *Function) Type() ObjectType {
@@ -100,12 +155,12 @@ func (f *Function) Type() ObjectType {
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
+	params := ast.ParameterStrings(f.Parameters, f.Defaults, f.Rest)
 
 	out.WriteString("fn")
+	if f.Name != "" {
+		out.WriteString(" " + f.Name)
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") {\n")
@@ -125,7 +180,8 @@ func (s *String) Inspect() string  { return s.Value }
 type BuiltinFunction func(args ...Object) Object
 
 type Builtin struct {
-	Fn BuiltinFunction
+	Name string
+	Fn   BuiltinFunction
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -210,3 +266,23 @@ func (h *Hash) Inspect() string {
 type Hashable interface {
 	HashKey() HashKey
 }
+
+type Module struct {
+	Name    string
+	Path    string
+	Exports map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string {
+	return fmt.Sprintf("<module %s>", m.Name)
+}
+
+type Regex struct {
+	Regexp *regexp.Regexp
+}
+
+func (r *Regex) Type() ObjectType { return REGEX_OBJ }
+func (r *Regex) Inspect() string {
+	return fmt.Sprintf("regex(%q)", r.Regexp.String())
+}
@@ -20,3 +20,20 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestErrorTraceback(t *testing.T) {
+	err := &Error{
+		Message: "type mismatch: INTEGER + STRING",
+		Stack: []Frame{
+			{Function: "inner", Line: 2, Column: 8},
+			{Function: "<anonymous>", Line: 5, Column: 3},
+		},
+	}
+
+	expected := "ERROR: type mismatch: INTEGER + STRING\n" +
+		"    at inner (line 2, column 8)\n" +
+		"    at <anonymous> (line 5, column 3)"
+	if err.Traceback() != expected {
+		t.Errorf("wrong traceback. expected=%q, got=%q", expected, err.Traceback())
+	}
+}
@@ -0,0 +1,252 @@
+// Package optimizer rewrites a parsed program into an equivalent one that
+// is cheaper to evaluate: constant expressions are folded into literals,
+// if expressions with a constant condition are replaced by the branch that
+// runs, and statements after a return are dropped.
+//
+// Expressions whose evaluation would fail at run time, such as integer
+// division by zero, are left as they are so that the error is still
+// reported when (and only when) the expression runs.
+package optimizer
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// Optimize rewrites program in place and returns it.
+func Optimize(program *ast.Program) *ast.Program {
+	return ast.Modify(program, optimize).(*ast.Program)
+}
+
+// optimize rewrites a single node whose children are already optimized.
+func optimize(node ast.Node) ast.Node {
+	switch node := node.(type) {
+	case *ast.Program:
+		node.Statements = optimizeStatements(node.Statements)
+	case *ast.BlockStatement:
+		node.Statements = optimizeStatements(node.Statements)
+	case *ast.CaseStatement:
+		node.Statements = optimizeStatements(node.Statements)
+	case *ast.PrefixExpression:
+		if folded := foldPrefix(node); folded != nil {
+			return folded
+		}
+	case *ast.InfixExpression:
+		if folded := foldInfix(node); folded != nil {
+			return folded
+		}
+	case *ast.IfExpression:
+		// 式の位置では枝が1つの式だけの場合に限り置き換える
+		if branch, ok := constantBranch(node); ok && branch != nil && len(branch.Statements) == 1 {
+			if es, ok := branch.Statements[0].(*ast.ExpressionStatement); ok {
+				return es.Expression
+			}
+		}
+	}
+	return node
+}
+
+// optimizeStatements inlines if statements with a constant condition and
+// drops the statements after a return.
+func optimizeStatements(stmts []ast.Statement) []ast.Statement {
+	result := []ast.Statement{}
+	for i, stmt := range stmts {
+		last := i == len(stmts)-1
+
+		// if (true) {...} を文として書いた場合はブロックの中身を展開する.
+		// ブロックは新しい環境を作らないので意味は変わらない
+		if es, ok := stmt.(*ast.ExpressionStatement); ok {
+			if ie, ok := es.Expression.(*ast.IfExpression); ok {
+				if branch, ok := constantBranch(ie); ok {
+					if branch != nil && len(branch.Statements) != 0 {
+						result = append(result, branch.Statements...)
+						if returns(result) {
+							break
+						}
+						continue
+					}
+					// 最後の文は値 (null) が結果になるので残す
+					if !last {
+						continue
+					}
+				}
+			}
+		}
+
+		result = append(result, stmt)
+		if returns(result) {
+			// return 以降の文は実行されない
+			break
+		}
+	}
+	return result
+}
+
+// returns reports whether the last statement of stmts is a return.
+func returns(stmts []ast.Statement) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	_, ok := stmts[len(stmts)-1].(*ast.ReturnStatement)
+	return ok
+}
+
+// constantBranch returns the branch of ie that runs when its condition is
+// a boolean literal. The branch is nil for a false condition without else.
+func constantBranch(ie *ast.IfExpression) (*ast.BlockStatement, bool) {
+	cond, ok := ie.Condition.(*ast.Boolean)
+	if !ok {
+		return nil, false
+	}
+	if cond.Value {
+		return ie.Consequence, true
+	}
+	return ie.Alternative, true
+}
+
+func foldPrefix(exp *ast.PrefixExpression) ast.Expression {
+	switch exp.Operator {
+	case "-":
+		switch right := exp.Right.(type) {
+		case *ast.IntegerLiteral:
+			return integerLiteral(exp.Token, -right.Value)
+		case *ast.FloatLiteral:
+			return floatLiteral(exp.Token, -right.Value)
+		}
+	case "!":
+		// 真偽値以外のリテラルは全て truthy なので !x は false
+		switch right := exp.Right.(type) {
+		case *ast.Boolean:
+			return booleanLiteral(exp.Token, !right.Value)
+		case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral:
+			return booleanLiteral(exp.Token, false)
+		}
+	}
+	return nil
+}
+
+func foldInfix(exp *ast.InfixExpression) ast.Expression {
+	switch left := exp.Left.(type) {
+	case *ast.IntegerLiteral:
+		switch right := exp.Right.(type) {
+		case *ast.IntegerLiteral:
+			return foldInteger(exp, left.Value, right.Value)
+		case *ast.FloatLiteral:
+			return foldFloat(exp, float64(left.Value), right.Value)
+		}
+	case *ast.FloatLiteral:
+		switch right := exp.Right.(type) {
+		case *ast.IntegerLiteral:
+			return foldFloat(exp, left.Value, float64(right.Value))
+		case *ast.FloatLiteral:
+			return foldFloat(exp, left.Value, right.Value)
+		}
+	case *ast.StringLiteral:
+		if right, ok := exp.Right.(*ast.StringLiteral); ok && exp.Operator == "+" {
+			return &ast.StringLiteral{
+				Token: token.Token{Type: token.STRING, Literal: left.Value + right.Value, Line: exp.Token.Line, Column: exp.Token.Column},
+				Value: left.Value + right.Value,
+			}
+		}
+	case *ast.Boolean:
+		if right, ok := exp.Right.(*ast.Boolean); ok {
+			switch exp.Operator {
+			case "&&":
+				return booleanLiteral(exp.Token, left.Value && right.Value)
+			case "==":
+				return booleanLiteral(exp.Token, left.Value == right.Value)
+			case "!=":
+				return booleanLiteral(exp.Token, left.Value != right.Value)
+			}
+		}
+	}
+	return nil
+}
+
+func foldInteger(exp *ast.InfixExpression, left, right int64) ast.Expression {
+	switch exp.Operator {
+	case "+":
+		return integerLiteral(exp.Token, left+right)
+	case "-":
+		return integerLiteral(exp.Token, left-right)
+	case "*":
+		return integerLiteral(exp.Token, left*right)
+	case "/":
+		if right != 0 {
+			return integerLiteral(exp.Token, left/right)
+		}
+	case "%":
+		if right != 0 {
+			return integerLiteral(exp.Token, left%right)
+		}
+	case "<":
+		return booleanLiteral(exp.Token, left < right)
+	case ">":
+		return booleanLiteral(exp.Token, left > right)
+	case "==":
+		return booleanLiteral(exp.Token, left == right)
+	case "!=":
+		return booleanLiteral(exp.Token, left != right)
+	}
+	return nil
+}
+
+func foldFloat(exp *ast.InfixExpression, left, right float64) ast.Expression {
+	switch exp.Operator {
+	case "+":
+		return floatLiteral(exp.Token, left+right)
+	case "-":
+		return floatLiteral(exp.Token, left-right)
+	case "*":
+		return floatLiteral(exp.Token, left*right)
+	case "/":
+		return floatLiteral(exp.Token, left/right)
+	case "%":
+		return floatLiteral(exp.Token, math.Mod(left, right))
+	case "<":
+		return booleanLiteral(exp.Token, left < right)
+	case ">":
+		return booleanLiteral(exp.Token, left > right)
+	case "==":
+		return booleanLiteral(exp.Token, left == right)
+	case "!=":
+		return booleanLiteral(exp.Token, left != right)
+	}
+	return nil
+}
+
+// 置き換えたリテラルのトークンは元の式の位置を引き継ぐ
+
+func integerLiteral(pos token.Token, value int64) ast.Expression {
+	return &ast.IntegerLiteral{
+		Token: token.Token{Type: token.INT, Literal: strconv.FormatInt(value, 10), Line: pos.Line, Column: pos.Column},
+		Value: value,
+	}
+}
+
+// floatLiteral returns nil for values that have no literal form (NaN and
+// infinities), leaving the expression to be computed at run time.
+func floatLiteral(pos token.Token, value float64) ast.Expression {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	literal := strconv.FormatFloat(value, 'f', -1, 64)
+	if _, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		literal += ".0"
+	}
+	return &ast.FloatLiteral{
+		Token: token.Token{Type: token.FLOAT, Literal: literal, Line: pos.Line, Column: pos.Column},
+		Value: value,
+	}
+}
+
+func booleanLiteral(pos token.Token, value bool) ast.Expression {
+	tok := token.Token{Type: token.FALSE, Literal: "false", Line: pos.Line, Column: pos.Column}
+	if value {
+		tok.Type, tok.Literal = token.TRUE, "true"
+	}
+	return &ast.Boolean{Token: tok, Value: value}
+}
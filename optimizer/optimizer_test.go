@@ -0,0 +1,89 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors: %v", len(p.Errors()), p.Errors())
+	}
+	return program
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"60 * 60 * 24", "86400"},
+		{"x * (60 * 60)", "(x * 3600)"},
+		{"-(2 + 3)", "-5"},
+		{"1.5 * 2", "3.0"},
+		{"1 / 2.0", "0.5"},
+		{"1 / 0", "(1 / 0)"},
+		{"5 % 0", "(5 % 0)"},
+		{"1.0 / 0", "(1.0 / 0)"},
+		{`"foo" + "bar" + "baz"`, `"foobarbaz"`},
+		{`"foo" == "foo"`, `("foo" == "foo")`},
+		{"true && !false", "true"},
+		{"1 < 2 == true", "true"},
+		{"!5", "false"},
+		{"if (1 < 2) { x } else { y }", "x"},
+		{"if (false) { x } else { y }", "y"},
+		{"let a = if (true) { 1 };", "let a = 1;"},
+		{"if (true) { let a = 1; a }", "let a = 1;a"},
+		{"if (false) { x }; y", "y"},
+		{"if (false) { x }", "if (false) { x }"},
+		{"let f = fn() { return 1; x; y };", "let f = fn() { return 1; };"},
+		{"let f = fn() { if (true) { return 1; } x };", "let f = fn() { return 1; };"},
+		{"return 1; x", "return 1;"},
+		{"for (let i = 0; i < 2 * 5; ++i) { puts(i) }", "for (let i = 0; (i < 10); ++i) { puts(i) }"},
+		{"len([1 + 1, 2 * 3])", "len([2, 6])"},
+		{"let f = fn(a = 2 * 2) { a };", "let f = fn(a = 4) { a };"},
+	}
+
+	for _, tt := range tests {
+		program := Optimize(parse(t, tt.input))
+		if program.String() != tt.expected {
+			t.Errorf("%q: wrong optimized program. got=%q, want=%q", tt.input, program.String(), tt.expected)
+		}
+	}
+}
+
+func TestOptimizePreservesSemantics(t *testing.T) {
+	tests := []string{
+		"60 * 60 * 24",
+		"let secs = fn(days) { days * 60 * 60 * 24 }; secs(2)",
+		"-(2 + 3) * 4 % 3",
+		"1.5 * 2 + 1",
+		`"foo" + "bar"`,
+		"(true && false) == false",
+		"if (true && false) { 1 } else { 2 }",
+		"!(1 < 2)",
+		"if (false) { 1 }",
+		"let x = 1; if (false) { 2 }",
+		"let f = fn(n) { if (true) { return n * 2; } n }; f(21)",
+		"let f = fn() { return 1; 2 }; f()",
+		"let total = 0; let f = fn() { let sum = 0; for (let i = 0; i < 2 * 5; ++i) { let sum = sum + i * (2 + 3); } sum }; f()",
+		"1 / 0.0 > 1000",
+		"[1 + 1, 2 * 3][1 - 0]",
+		`{"a" + "b": 1 + 2}["ab"]`,
+	}
+
+	for _, input := range tests {
+		expected := evaluator.Eval(parse(t, input), object.NewEnvironment())
+		optimized := evaluator.Eval(Optimize(parse(t, input)), object.NewEnvironment())
+		if expected.Inspect() != optimized.Inspect() {
+			t.Errorf("%q: optimized program evaluates to %s, want %s", input, optimized.Inspect(), expected.Inspect())
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/internal/fuzzseed"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+)
+
+// FuzzParseProgram checks that parsing terminates and that the String()
+// of a program without errors parses back to the same program.
+func FuzzParseProgram(f *testing.F) {
+	fuzzseed.Add(f,
+		"switch x { case 1: x",
+		"switch { case true: a case false: b }",
+		`let s = "a\"b\n"; {s: [1, 2]}[s]`,
+		"for (let i = 0; i < 3; ++i) { if (i) { puts(i) } else { 0 } }",
+		"fn f(a, b = 1, ...c): int { return a; }; f(1)(2)",
+		`import { a, b as c } from "m"; export let x = m.y;`,
+	)
+	f.Fuzz(func(t *testing.T, input string) {
+		program, errors := parse(t, input)
+		if len(errors) != 0 {
+			return
+		}
+		printed := program.String()
+		again, errors := parse(t, printed)
+		if len(errors) != 0 {
+			t.Fatalf("String() of %q does not parse.\nString(): %q\nerror: %s", input, printed, errors[0])
+		}
+		if got := again.String(); got != printed {
+			t.Fatalf("String() of %q parses to a different program.\nfirst:  %q\nsecond: %q", input, printed, got)
+		}
+	})
+}
+
+// parse parses input, failing t if the parser does not finish in time.
+func parse(t *testing.T, input string) (*ast.Program, []string) {
+	var program *ast.Program
+	var errors []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p := New(lexer.New(input))
+		program = p.ParseProgram()
+		errors = p.Errors()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ParseProgram(%q) does not terminate", input)
+	}
+	return program, errors
+}
@@ -36,6 +36,7 @@ var precedences = map[token.TokenType]int{
 	token.PERCENT:    PRODUCT,
 	token.LPAREN:     CALL,
 	token.LBRACKET:   INDEX,
+	token.DOT:        INDEX,
 }
 
 type Parser struct {
@@ -65,6 +66,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
@@ -76,6 +78,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
 
 	// 中置記号となるもののパーサを登録
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -91,6 +94,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.DOUBLE_AND, p.parseInfixExpression)
+	p.registerInfix(token.DOT, p.parseDotExpression)
 	return p
 }
 
@@ -136,6 +140,10 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseDoublePlusStatement()
 	case token.SWITCH:
 		return p.parseSwitchStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	default:
 		return p.ParseExpressionStatement()
 	}
@@ -150,12 +158,19 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	// let name: <type> = ...
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		p.nextToken()
+		if stmt.Type = p.parseType(); stmt.Type == nil {
+			return nil
+		}
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 
-	// TODO: セミコロンになるまで式を呼び飛ばしてしまっている
-
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
 
@@ -163,10 +178,6 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		p.nextToken()
 	}
 
-	for !p.curTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
-
 	return stmt
 }
 
@@ -177,7 +188,7 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -271,6 +282,20 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit.Value = value
 	return lit
 }
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{
+		Token: p.curToken,
+	}
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	// defer untrace(trace("parsePrefixExpression"))
 	expression := &ast.PrefixExpression{
@@ -427,6 +452,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		}
 		p.nextToken()
 	}
+	block.End = p.curToken
 
 	return block
 }
@@ -438,13 +464,30 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		Token: p.curToken,
 	}
 
+	// fn name(...) {...} の関数宣言
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		lit.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
 	// fnの次は `(` でなければエラー
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
 
 	// 引数のパース
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
+
+	// fn(...): <type> の戻り値の型注釈
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		p.nextToken()
+		if lit.ReturnType = p.parseType(); lit.ReturnType == nil {
+			return nil
+		}
+	}
 
 	// (...) で次は `{` でなければエラー
 	if !p.expectPeek(token.LBRACE) {
@@ -457,39 +500,166 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
+	lit.ParameterTypes = []ast.TypeExpression{}
+	lit.Defaults = []ast.Expression{}
 
 	// fn() のようにパラメータがなかったら空配列で返す
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return true
 	}
 
-	p.nextToken()
+	for {
+		// 変数名へ
+		p.nextToken()
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+		// ...rest は最後の引数でなければならない
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			lit.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if p.peekTokenIs(token.COLON) {
+				p.nextToken()
+				p.nextToken()
+				if lit.RestType = p.parseType(); lit.RestType == nil {
+					return false
+				}
+			}
+			break
+		}
 
-	for p.peekTokenIs(token.COMMA) {
+		if !p.curTokenIs(token.IDENT) {
+			msg := fmt.Sprintf("expected parameter name, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return false
+		}
+		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		// name: <type>
+		var typ ast.TypeExpression
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			if typ = p.parseType(); typ == nil {
+				return false
+			}
+		}
+
+		// name = <default>
+		var def ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			def = p.parseExpression(LOWEST)
+		} else if len(lit.Defaults) > 0 && lit.Defaults[len(lit.Defaults)-1] != nil {
+			msg := fmt.Sprintf("parameter %s without default value follows parameter with default value", ident.Value)
+			p.errors = append(p.errors, msg)
+			return false
+		}
+
+		// 変数名を配列に格納
+		lit.Parameters = append(lit.Parameters, ident)
+		lit.ParameterTypes = append(lit.ParameterTypes, typ)
+		lit.Defaults = append(lit.Defaults, def)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		// 次のカンマへ
 		p.nextToken()
-		// カンマの次の変数名へ
+	}
+
+	// 次の文字が `)` で閉じてなかったら駄目
+	return p.expectPeek(token.RPAREN)
+}
+
+// parseType parses a type annotation starting at the current token:
+// a type name, [<element>], {<key>: <value>} or fn(<param>, ...<rest>): <return>.
+// Type names are not checked here; the checker reports unknown ones.
+func (p *Parser) parseType() ast.TypeExpression {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+	case token.LBRACKET:
+		typ := &ast.ArrayType{Token: p.curToken}
 		p.nextToken()
-		// 変数名パース
-		ident := &ast.Identifier{
-			Token: p.curToken, Value: p.curToken.Literal,
+		if typ.Element = p.parseType(); typ.Element == nil {
+			return nil
 		}
-		// 変数名を配列に格納
-		identifiers = append(identifiers, ident)
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return typ
+	case token.LBRACE:
+		typ := &ast.HashType{Token: p.curToken}
+		p.nextToken()
+		if typ.Key = p.parseType(); typ.Key == nil {
+			return nil
+		}
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		if typ.Value = p.parseType(); typ.Value == nil {
+			return nil
+		}
+		if !p.expectPeek(token.RBRACE) {
+			return nil
+		}
+		return typ
+	case token.FUNCTION:
+		return p.parseFunctionType()
 	}
 
-	// 次の文字が `)` で閉じてなかったら駄目
+	msg := fmt.Sprintf("expected type, got %s instead", p.curToken.Type)
+	p.errors = append(p.errors, msg)
+	return nil
+}
+
+func (p *Parser) parseFunctionType() ast.TypeExpression {
+	typ := &ast.FunctionType{Token: p.curToken, Parameters: []ast.TypeExpression{}}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+
+		// ...<type> は最後の引数でなければならない
+		if p.curTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if typ.Rest = p.parseType(); typ.Rest == nil {
+				return nil
+			}
+			break
+		}
+
+		param := p.parseType()
+		if param == nil {
+			return nil
+		}
+		typ.Parameters = append(typ.Parameters, param)
+
+		if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
-	return identifiers
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		p.nextToken()
+		if typ.Return = p.parseType(); typ.Return == nil {
+			return nil
+		}
+	}
+
+	return typ
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
@@ -524,6 +694,7 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.End = p.curToken
 	return array
 }
 
@@ -568,29 +739,27 @@ func (p *Parser) parseSwitchStatement() ast.Statement {
 		Case:  []*ast.CaseStatement{},
 	}
 	if !p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
 		exp := p.parseExpression(LOWEST)
 		stmt.Expression = exp
 	} else {
 		stmt.Expression = nil
 	}
-	p.nextToken()
-	for !p.peekTokenIs(token.RBRACE) {
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	for p.peekTokenIs(token.CASE) {
 		p.nextToken()
-		switch {
-		case p.curTokenIs(token.CASE):
-			s := p.parseCaseStatement()
-			caseStmt, ok := s.(*ast.CaseStatement)
-			if !ok {
-				return nil
-			}
-			stmt.Case = append(stmt.Case, caseStmt)
-		}
+		stmt.Case = append(stmt.Case, p.parseCaseStatement())
 	}
-	p.nextToken()
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	stmt.End = p.curToken
 	return stmt
 }
 
-func (p *Parser) parseCaseStatement() ast.Statement {
+func (p *Parser) parseCaseStatement() *ast.CaseStatement {
 	stmt := &ast.CaseStatement{
 		Token:      p.curToken,
 		Statements: []ast.Statement{},
@@ -601,10 +770,22 @@ func (p *Parser) parseCaseStatement() ast.Statement {
 	if p.peekTokenIs(token.COLON) {
 		p.nextToken()
 	}
-	for !p.peekTokenIs(token.BREAK) {
+
+	// break, 次の case, switch の終わりまでが case の本体
+	for !p.peekTokenIs(token.BREAK) && !p.peekTokenIs(token.CASE) &&
+		!p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
 		p.nextToken()
 		caseStmts := p.parseStatement()
-		stmt.Statements = append(stmt.Statements, caseStmts)
+		if caseStmts != nil {
+			stmt.Statements = append(stmt.Statements, caseStmts)
+		}
+	}
+	if p.peekTokenIs(token.BREAK) {
+		p.nextToken()
+		stmt.Break = true
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
 	}
 
 	return stmt
@@ -626,6 +807,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		value := p.parseExpression(LOWEST)
 
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
@@ -635,6 +817,125 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	if !p.expectPeek(token.RBRACE) {
 		return nil
 	}
+	hash.End = p.curToken
 
 	return hash
 }
+
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	// import { a, b as c } from "path";
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Names = p.parseImportNames()
+		if stmt.Names == nil {
+			return nil
+		}
+		if !p.expectPeekIdent("from") {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+
+	// import "path" as name;
+	if stmt.Names == nil && p.peekTokenIs(token.IDENT) && p.peekToken.Literal == "as" {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseImportNames() []*ast.ImportName {
+	names := []*ast.ImportName{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		name := &ast.ImportName{
+			Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+		}
+		if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == "as" {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			name.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		}
+		names = append(names, name)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	if len(names) == 0 {
+		p.errors = append(p.errors, "import list must name at least one binding")
+		return nil
+	}
+
+	return names
+}
+
+// expectPeekIdent is expectPeek for contextual keywords such as `from`,
+// which are lexed as plain identifiers.
+func (p *Parser) expectPeekIdent(literal string) bool {
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == literal {
+		p.nextToken()
+		return true
+	}
+	msg := fmt.Sprintf("expected next token to be %q, got %s instead", literal, p.peekToken.Type)
+	p.errors = append(p.errors, msg)
+	return false
+}
+
+func (p *Parser) parseExportStatement() ast.Statement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LET) {
+		return nil
+	}
+	let := p.parseLetStatement()
+	if let == nil {
+		return nil
+	}
+	stmt.Statement = let
+
+	return stmt
+}
+
+func (p *Parser) parseDotExpression(left ast.Expression) ast.Expression {
+	exp := &ast.DotExpression{Token: p.curToken, Left: left}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	exp := &ast.SpreadExpression{Token: p.curToken}
+
+	p.nextToken()
+	exp.Value = p.parseExpression(PREFIX)
+
+	return exp
+}
@@ -110,6 +110,46 @@ func TestReturnStatement(t *testing.T) {
 	}
 }
 
+func TestReturnStatementWithoutSemicolon(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"return 5", "return 5;"},
+		{"fn() { return 1 }; 2", "fn() { return 1; };2"},
+		{"if (x) { return x } else { return 0 }", "if (x) { return x; } else { return 0; }"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if program.String() != tt.expected {
+			t.Errorf("%q: wrong program. got=%q, want=%q", tt.input, program.String(), tt.expected)
+		}
+	}
+}
+
+func TestLetStatementWithoutSemicolon(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = 1", "let a = 1;"},
+		{"let a = 1\nlet b = a\nb", "let a = 1;let b = a;b"},
+		{"let f = fn() { let x = 1\nx }; f()", "let f = fn() { let x = 1;x };f()"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if program.String() != tt.expected {
+			t.Errorf("%q: wrong program. got=%q, want=%q", tt.input, program.String(), tt.expected)
+		}
+	}
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	input := "foobar;"
 
@@ -311,7 +351,7 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		},
 		{
 			"3 + 4; -5 * 5",
-			"(3 + 4)((-5) * 5)",
+			"(3 + 4);((-5) * 5)",
 		},
 		{
 			"5 > 4 == 3 < 4",
@@ -770,6 +810,7 @@ func TestSwitchStatement(t *testing.T) {
 		l := lexer.New(c)
 		p := New(l)
 		program := p.ParseProgram()
+		checkParserErrors(t, p)
 		stmt, ok := program.Statements[0].(*ast.SwitchStatement)
 		if !ok {
 			t.Fatalf("stmt not *ast.SwitchStatement. got=%T", program.Statements[0])
@@ -780,6 +821,80 @@ func TestSwitchStatement(t *testing.T) {
 	}
 }
 
+func TestSwitchSubject(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"switch x { case true: break; }", "x"},
+		{"switch x + 1 { case true: break; }", "(x + 1)"},
+		{`switch puts("hoge") { case true: break; }`, `puts("hoge")`},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt, ok := program.Statements[0].(*ast.SwitchStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.SwitchStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Expression == nil || stmt.Expression.String() != tt.expected {
+			t.Errorf("%q: wrong subject. got=%v, want=%q", tt.input, stmt.Expression, tt.expected)
+		}
+	}
+}
+
+func TestSwitchCaseWithoutBreak(t *testing.T) {
+	input := `switch x {
+	case x > 1:
+		puts("big")
+	case x == 1:
+		puts("one");
+		break;
+	case true:
+	}`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.SwitchStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.SwitchStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Expression.String() != "x" {
+		t.Errorf("stmt.Expression wrong. got=%s", stmt.Expression)
+	}
+
+	expected := []struct {
+		statements int
+		hasBreak   bool
+	}{
+		{1, false},
+		{1, true},
+		{0, false},
+	}
+	if len(stmt.Case) != len(expected) {
+		t.Fatalf("stmt.Case length wrong. got=%d, want=%d", len(stmt.Case), len(expected))
+	}
+	for i, tt := range expected {
+		c := stmt.Case[i]
+		if len(c.Statements) != tt.statements || c.Break != tt.hasBreak {
+			t.Errorf("case %d: got %d statements, break=%t. want %d statements, break=%t",
+				i, len(c.Statements), c.Break, tt.statements, tt.hasBreak)
+		}
+	}
+}
+
+func TestUnterminatedSwitch(t *testing.T) {
+	p := New(lexer.New("switch { case true: puts(1)"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected a parse error for an unterminated switch")
+	}
+}
+
 func TestParsingHashLiteralsStringKeys(t *testing.T) {
 	input := `{"one": 1, "two": 2, "three": 3}`
 
@@ -809,7 +924,7 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
 		}
 
-		expectedValue := expected[literal.String()]
+		expectedValue := expected[literal.Value]
 		testIntegerLiteral(t, value, expectedValue)
 	}
 }
@@ -867,11 +982,237 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
 		}
 
-		testFunc, ok := tests[literal.String()]
+		testFunc, ok := tests[literal.Value]
 		if !ok {
-			t.Errorf("No test function for key %q found", literal.String())
+			t.Errorf("No test function for key %q found", literal.Value)
 			continue
 		}
 		testFunc(value)
 	}
 }
+
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedPath  string
+		expectedAlias string
+		expectedNames []string
+	}{
+		{`import "lib.monkey";`, "lib.monkey", "", nil},
+		{`import "path/to/lib.monkey" as lib;`, "path/to/lib.monkey", "lib", nil},
+		{`import { add, sub as minus } from "lib";`, "lib", "", []string{"add", "minus"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ImportStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Path.Value != tt.expectedPath {
+			t.Errorf("stmt.Path wrong. expected=%q, got=%q", tt.expectedPath, stmt.Path.Value)
+		}
+		if tt.expectedAlias != "" && (stmt.Alias == nil || stmt.Alias.Value != tt.expectedAlias) {
+			t.Errorf("stmt.Alias wrong. expected=%q, got=%v", tt.expectedAlias, stmt.Alias)
+		}
+		if len(stmt.Names) != len(tt.expectedNames) {
+			t.Fatalf("stmt.Names has wrong length. got=%d", len(stmt.Names))
+		}
+		for i, name := range tt.expectedNames {
+			if stmt.Names[i].Binding().Value != name {
+				t.Errorf("stmt.Names[%d] wrong. expected=%q, got=%q", i, name, stmt.Names[i].Binding().Value)
+			}
+		}
+		if stmt.String() != tt.input {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.input, stmt.String())
+		}
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	input := `export let add = fn(a, b) { a + b };`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.ExportStatement. got=%T", program.Statements[0])
+	}
+	testLetStatement(t, stmt.Statement, "add")
+}
+
+func TestDotExpression(t *testing.T) {
+	input := `lib.add(1, 2)`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("exp is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+	dot, ok := call.Function.(*ast.DotExpression)
+	if !ok {
+		t.Fatalf("call.Function is not ast.DotExpression. got=%T", call.Function)
+	}
+	testIdentifier(t, dot.Left, "lib")
+	testIdentifier(t, dot.Name, "add")
+}
+
+func TestFunctionDefaultAndRestParameters(t *testing.T) {
+	input := `fn(a, b = 10, ...rest) { a }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+	if len(function.Parameters) != 2 || len(function.Defaults) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d", len(function.Parameters))
+	}
+	testIdentifier(t, function.Parameters[0], "a")
+	testIdentifier(t, function.Parameters[1], "b")
+	if function.Defaults[0] != nil {
+		t.Errorf("parameter a has a default value. got=%s", function.Defaults[0])
+	}
+	testIntegerLiteral(t, function.Defaults[1], 10)
+	if function.Rest == nil || function.Rest.Value != "rest" {
+		t.Errorf("function.Rest is not rest. got=%v", function.Rest)
+	}
+	if function.String() != "fn(a, b = 10, ...rest) { a }" {
+		t.Errorf("function.String() wrong. got=%q", function.String())
+	}
+}
+
+func TestFunctionParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fn(a = 1, b) { a }`, "parameter b without default value follows parameter with default value"},
+		{`fn(...rest, a) { a }`, "expected next token to be ), got , instead"},
+		{`fn(1) { 1 }`, "expected parameter name, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors. expected=%q, got=%q", tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestSpreadExpression(t *testing.T) {
+	input := `add(1, ...rest)`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+	spread, ok := call.Arguments[1].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("argument is not ast.SpreadExpression. got=%T", call.Arguments[1])
+	}
+	testIdentifier(t, spread.Value, "rest")
+}
+
+func TestFunctionDeclarationParsing(t *testing.T) {
+	input := `fn add(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+	if function.Name == nil {
+		t.Fatalf("function.Name is nil")
+	}
+	testIdentifier(t, function.Name, "add")
+	if function.String() != "fn add(x, y) { (x + y) }" {
+		t.Errorf("function.String() wrong. got=%q", function.String())
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let x: int = 5;`, "let x: int = 5;"},
+		{`let xs: [string] = [];`, "let xs: [string] = [];"},
+		{`let h: {string: [float]} = {};`, "let h: {string: [float]} = {};"},
+		{`let f: fn(int, ...[string]): bool = g;`, "let f: fn(int, ...[string]): bool = g;"},
+		{`let f: fn() = g;`, "let f: fn() = g;"},
+		{`fn(a: int, b: string = "x", ...rest: [any]): bool { a }`, `fn(a: int, b: string = "x", ...rest: [any]): bool { a }`},
+		{`fn add(a: int, b): fn(int): int { a }`, "fn add(a: int, b): fn(int): int { a }"},
+		{`fn(f: fn(int): int, x) { x }`, "fn(f: fn(int): int, x) { x }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: program has %d statements", tt.input, len(program.Statements))
+		}
+		if got := program.Statements[0].String(); got != tt.expected {
+			t.Errorf("%q: wrong String(). got=%q, want=%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let x: = 5;`, "expected type, got = instead"},
+		{`let x: [int = 5;`, "expected next token to be ], got = instead"},
+		{`let x: {int} = 5;`, "expected next token to be :, got } instead"},
+		{`fn(a: 1) { a }`, "expected type, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors. expected=%q, got=%q", tt.expected, p.Errors())
+		}
+	}
+}
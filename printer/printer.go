@@ -0,0 +1,622 @@
+// Package printer prints Monkey programs in a canonical style: one
+// statement per line, tab indentation, single spaces around binary
+// operators and after commas, and only the parentheses the grammar needs.
+// Comments collected by the lexer are put back next to the statements
+// they were written with, or the elements of an array or hash literal,
+// which is then printed one element per line, and single blank lines
+// between statements are kept. Formatting formatted code leaves it unchanged.
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/token"
+)
+
+// 括弧の要否を決める優先順位 (parser と同じ並び)
+const (
+	_ int = iota
+	lowest
+	andOr
+	equals
+	lessGreater
+	sum
+	product
+	prefix
+	call
+	atom
+)
+
+var precedences = map[string]int{
+	"&&": andOr,
+	"==": equals,
+	"!=": equals,
+	"<":  lessGreater,
+	">":  lessGreater,
+	"+":  sum,
+	"-":  sum,
+	"*":  product,
+	"/":  product,
+	"%":  product,
+}
+
+// Format parses src and returns it formatted.
+func Format(src []byte) ([]byte, error) {
+	l := lexer.New(string(src))
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	var buf bytes.Buffer
+	if err := Fprint(&buf, program, l.Comments()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Fprint writes program to w. Comments must be in source order, as
+// returned by lexer.Comments.
+func Fprint(w io.Writer, program *ast.Program, comments []token.Token) error {
+	p := &printer{comments: comments}
+	p.statements(program.Statements, math.MaxInt32)
+	if p.buf.Len() > 0 {
+		p.write("\n")
+	}
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+type printer struct {
+	buf    bytes.Buffer
+	indent int
+
+	comments []token.Token
+	next     int // 次に出力するコメント
+
+	// 最後に出力した文やコメントの元のソースでの行番号.
+	// 0 はブロックの先頭 (空行を入れない)
+	lastLine int
+}
+
+func (p *printer) write(s string) {
+	p.buf.WriteString(s)
+}
+
+func (p *printer) newline() {
+	p.write("\n" + strings.Repeat("\t", p.indent))
+}
+
+// separate starts a new line for content that was on line in the source,
+// keeping one blank line if there was at least one.
+func (p *printer) separate(line int) {
+	if p.buf.Len() == 0 {
+		return
+	}
+	if p.lastLine != 0 && line > p.lastLine+1 {
+		p.write("\n")
+	}
+	p.newline()
+}
+
+// flushComments prints the pending comments that start before line on
+// lines of their own.
+func (p *printer) flushComments(line int) {
+	for p.next < len(p.comments) && p.comments[p.next].Line < line {
+		c := p.comments[p.next]
+		p.separate(c.Line)
+		p.write(c.Literal)
+		p.lastLine = c.Line
+		p.next++
+	}
+}
+
+// commentBetween reports whether a pending comment lies between the
+// positions of from and to.
+func (p *printer) commentBetween(from, to token.Token) bool {
+	for _, c := range p.comments[p.next:] {
+		if before(c, to) && !before(c, from) {
+			return true
+		}
+	}
+	return false
+}
+
+func before(a, b token.Token) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// statements prints a statement list. Comments before end, the line of
+// the closing brace, are printed inside the list.
+func (p *printer) statements(stmts []ast.Statement, end int) {
+	for i, stmt := range stmts {
+		start, last := lines(stmt)
+		p.flushComments(start)
+		p.separate(start)
+
+		p.statement(stmt)
+		if i+1 < len(stmts) && needsSemicolon(stmt, stmts[i+1]) {
+			p.write(";")
+		}
+
+		p.trailingComments(last)
+	}
+	p.flushComments(end)
+}
+
+// trailingComments prints the pending comments up to line last, the last
+// line of what was just printed: the one on that line at the end of the
+// line and the ones before it on lines of their own after it.
+func (p *printer) trailingComments(last int) {
+	trailing := -1
+	for j := p.next; j < len(p.comments) && p.comments[j].Line <= last; j++ {
+		if p.comments[j].Line == last {
+			trailing = j
+		}
+	}
+	if trailing >= 0 {
+		p.write(" " + p.comments[trailing].Literal)
+	}
+	p.lastLine = last
+	for p.next < len(p.comments) && p.comments[p.next].Line <= last {
+		if p.next != trailing {
+			c := p.comments[p.next]
+			p.newline()
+			p.write(c.Literal)
+		}
+		p.next++
+	}
+}
+
+func (p *printer) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		p.let(stmt)
+		p.write(";")
+	case *ast.ReturnStatement:
+		p.write("return")
+		if stmt.ReturnValue != nil {
+			p.write(" ")
+			p.expression(stmt.ReturnValue, lowest)
+		}
+		p.write(";")
+	case *ast.ExpressionStatement:
+		p.expression(stmt.Expression, lowest)
+		if !endsWithBlock(stmt.Expression) {
+			p.write(";")
+		}
+	case *ast.BlockStatement:
+		p.block(stmt)
+	case *ast.DoublePlusStatement:
+		p.write("++" + stmt.Name.Value + ";")
+	case *ast.SwitchStatement:
+		p.switchStatement(stmt)
+	case *ast.ImportStatement:
+		p.importStatement(stmt)
+	case *ast.ExportStatement:
+		p.write("export ")
+		p.let(stmt.Statement)
+		p.write(";")
+	}
+}
+
+func (p *printer) let(stmt *ast.LetStatement) {
+	p.write("let " + stmt.Name.Value)
+	if stmt.Type != nil {
+		p.write(": " + stmt.Type.String())
+	}
+	p.write(" = ")
+	p.expression(stmt.Value, lowest)
+}
+
+// clause prints the init or step statement of a for loop, which can be
+// any statement, without the semicolon that ends it.
+func (p *printer) clause(stmt ast.Statement) {
+	if stmt == nil {
+		return
+	}
+	q := &printer{indent: p.indent}
+	q.statement(stmt)
+	p.write(strings.TrimSuffix(q.buf.String(), ";"))
+}
+
+// endsWithBlock reports whether an expression statement is written
+// without a trailing semicolon, like if and for.
+func endsWithBlock(exp ast.Expression) bool {
+	switch exp := exp.(type) {
+	case *ast.IfExpression, *ast.ForExpression:
+		return true
+	case *ast.FunctionLiteral:
+		return exp.Name != nil
+	}
+	return false
+}
+
+// needsSemicolon reports whether a statement that is normally written
+// without a semicolon needs one because the next statement would otherwise
+// continue its expression (e.g. `if (x) {...}` followed by `-1`).
+func needsSemicolon(stmt, next ast.Statement) bool {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok || !endsWithBlock(es.Expression) {
+		return false
+	}
+	q := &printer{}
+	q.statement(next)
+	s := q.buf.String()
+	return s != "" && strings.ContainsAny(s[:1], "-([.")
+}
+
+func (p *printer) block(block *ast.BlockStatement) {
+	p.layoutBlock(block, p.inline(block))
+}
+
+// layoutBlock writes block on one line if inline is true, and indented
+// on its own lines otherwise.
+func (p *printer) layoutBlock(block *ast.BlockStatement, inline bool) {
+	if inline {
+		p.write("{ ")
+		p.expression(block.Statements[0].(*ast.ExpressionStatement).Expression, lowest)
+		p.write(" }")
+		return
+	}
+	if len(block.Statements) == 0 && !p.commentBetween(block.Token, block.End) {
+		p.write("{}")
+		return
+	}
+
+	p.write("{")
+	p.indent++
+	p.lastLine = 0
+	p.statements(block.Statements, block.End.Line)
+	p.indent--
+	p.newline()
+	p.write("}")
+	p.lastLine = block.End.Line
+}
+
+// inline reports whether block was written on one line and holds a
+// single expression that also fits on one line, like `fn(x) { x * 2 }`.
+func (p *printer) inline(block *ast.BlockStatement) bool {
+	if len(block.Statements) != 1 || block.Token.Line == 0 || block.Token.Line != block.End.Line {
+		return false
+	}
+	es, ok := block.Statements[0].(*ast.ExpressionStatement)
+	if !ok || p.commentBetween(block.Token, block.End) {
+		return false
+	}
+	q := &printer{}
+	q.expression(es.Expression, lowest)
+	return !strings.Contains(q.buf.String(), "\n")
+}
+
+func (p *printer) switchStatement(stmt *ast.SwitchStatement) {
+	p.write("switch ")
+	if stmt.Expression != nil {
+		p.expression(stmt.Expression, lowest)
+		p.write(" ")
+	}
+	p.write("{")
+	for _, c := range stmt.Case {
+		line, _ := lines(c)
+		p.lastLine = 0
+		p.flushComments(line)
+		p.separate(line)
+		p.write("case ")
+		p.expression(c.Condition, lowest)
+		p.write(":")
+		p.lastLine = line
+
+		p.indent++
+		p.statements(c.Statements, 0)
+		if c.Break {
+			p.newline()
+			p.write("break;")
+		}
+		p.indent--
+	}
+	p.lastLine = 0
+	p.flushComments(stmt.End.Line)
+	p.newline()
+	p.write("}")
+	p.lastLine = stmt.End.Line
+}
+
+func (p *printer) importStatement(stmt *ast.ImportStatement) {
+	p.write("import ")
+	if stmt.Names != nil {
+		names := []string{}
+		for _, n := range stmt.Names {
+			if n.Alias != nil {
+				names = append(names, n.Name.Value+" as "+n.Alias.Value)
+			} else {
+				names = append(names, n.Name.Value)
+			}
+		}
+		p.write("{ " + strings.Join(names, ", ") + " } from ")
+	}
+	p.write(quote(stmt.Path.Value))
+	if stmt.Alias != nil {
+		p.write(" as " + stmt.Alias.Value)
+	}
+	p.write(";")
+}
+
+// expression prints exp, parenthesized if it binds less tightly than the
+// surrounding context requires.
+func (p *printer) expression(exp ast.Expression, context int) {
+	parens := precedence(exp) < context
+	if parens {
+		p.write("(")
+	}
+
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		p.write(exp.Value)
+	case *ast.IntegerLiteral:
+		if exp.Token.Literal != "" && (exp.Token.Type == token.INT || exp.Token.Type == token.HEX) {
+			p.write(exp.Token.Literal)
+		} else {
+			p.write(strconv.FormatInt(exp.Value, 10))
+		}
+	case *ast.FloatLiteral:
+		if exp.Token.Literal != "" {
+			p.write(exp.Token.Literal)
+		} else {
+			p.write(strconv.FormatFloat(exp.Value, 'f', -1, 64))
+		}
+	case *ast.StringLiteral:
+		p.write(quote(exp.Value))
+	case *ast.Boolean:
+		p.write(strconv.FormatBool(exp.Value))
+	case *ast.PrefixExpression:
+		p.write(exp.Operator)
+		p.expression(exp.Right, prefix)
+	case *ast.InfixExpression:
+		prec := precedences[exp.Operator]
+		p.expression(exp.Left, prec)
+		p.write(" " + exp.Operator + " ")
+		// 左結合なので右辺は同じ優先順位でも括弧が要る
+		p.expression(exp.Right, prec+1)
+	case *ast.IfExpression:
+		p.write("if (")
+		p.expression(exp.Condition, lowest)
+		p.write(") ")
+		// 片方の分岐だけ 1 行にすると揃わないので, 両方 1 行に収まるときだけ 1 行で書く
+		inline := p.inline(exp.Consequence) && (exp.Alternative == nil || p.inline(exp.Alternative))
+		p.layoutBlock(exp.Consequence, inline)
+		if exp.Alternative != nil {
+			p.write(" else ")
+			p.layoutBlock(exp.Alternative, inline)
+		}
+	case *ast.ForExpression:
+		p.write("for (")
+		p.clause(exp.InitStatement)
+		p.write("; ")
+		p.expression(exp.FinishCondition, lowest)
+		p.write("; ")
+		p.clause(exp.LoopStatement)
+		p.write(") ")
+		p.block(exp.Consequence)
+	case *ast.FunctionLiteral:
+		p.write("fn")
+		if exp.Name != nil {
+			p.write(" " + exp.Name.Value)
+		}
+		p.write("(")
+		for i, param := range exp.Parameters {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.write(param.Value)
+			if i < len(exp.ParameterTypes) && exp.ParameterTypes[i] != nil {
+				p.write(": " + exp.ParameterTypes[i].String())
+			}
+			if i < len(exp.Defaults) && exp.Defaults[i] != nil {
+				p.write(" = ")
+				p.expression(exp.Defaults[i], lowest)
+			}
+		}
+		if exp.Rest != nil {
+			if len(exp.Parameters) > 0 {
+				p.write(", ")
+			}
+			p.write("..." + exp.Rest.Value)
+			if exp.RestType != nil {
+				p.write(": " + exp.RestType.String())
+			}
+		}
+		p.write(")")
+		if exp.ReturnType != nil {
+			p.write(": " + exp.ReturnType.String())
+		}
+		p.write(" ")
+		p.block(exp.Body)
+	case *ast.CallExpression:
+		p.expression(exp.Function, call)
+		p.write("(")
+		p.expressions(exp.Arguments)
+		p.write(")")
+	case *ast.ArrayLiteral:
+		if p.commented(exp.Token, exp.End) {
+			p.elements("[", "]", len(exp.Elements), func(i int) (int, int) {
+				return lines(exp.Elements[i])
+			}, func(i int) {
+				p.expression(exp.Elements[i], lowest)
+			}, exp.End)
+			break
+		}
+		p.write("[")
+		p.expressions(exp.Elements)
+		p.write("]")
+	case *ast.IndexExpression:
+		p.expression(exp.Left, call)
+		p.write("[")
+		p.expression(exp.Index, lowest)
+		p.write("]")
+	case *ast.HashLiteral:
+		keys := exp.OrderedKeys()
+		if p.commented(exp.Token, exp.End) {
+			p.elements("{", "}", len(keys), func(i int) (int, int) {
+				first, _ := lines(keys[i])
+				_, last := lines(exp.Pairs[keys[i]])
+				return first, last
+			}, func(i int) {
+				p.expression(keys[i], lowest)
+				p.write(": ")
+				p.expression(exp.Pairs[keys[i]], lowest)
+			}, exp.End)
+			break
+		}
+		p.write("{")
+		for i, key := range keys {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expression(key, lowest)
+			p.write(": ")
+			p.expression(exp.Pairs[key], lowest)
+		}
+		p.write("}")
+	case *ast.DotExpression:
+		p.expression(exp.Left, call)
+		p.write("." + exp.Name.Value)
+	case *ast.SpreadExpression:
+		p.write("...")
+		p.expression(exp.Value, prefix)
+	}
+
+	if parens {
+		p.write(")")
+	}
+}
+
+func (p *printer) expressions(exps []ast.Expression) {
+	for i, exp := range exps {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expression(exp, lowest)
+	}
+}
+
+// commented reports whether a literal running from open to its closing
+// bracket end holds comments. Literals built by the optimizer have no
+// positions and hold none.
+func (p *printer) commented(open, end token.Token) bool {
+	return end.Line != 0 && p.commentBetween(open, end)
+}
+
+// elements prints the n elements of an array or hash literal with
+// comments inside it one per line, so that each comment stays with the
+// element it was written after. span returns the first and last source
+// lines of element i and element prints it.
+func (p *printer) elements(open, close string, n int, span func(i int) (int, int), element func(i int), end token.Token) {
+	// 閉じ括弧より後のコメントは外側の文のものなので見えなくしておく
+	all := p.comments
+	inside := p.next
+	for inside < len(all) && before(all[inside], end) {
+		inside++
+	}
+	p.comments = all[:inside]
+	defer func() { p.comments = all }()
+
+	p.write(open)
+	p.indent++
+	p.lastLine = 0
+	for i := 0; i < n; i++ {
+		start, last := span(i)
+		p.flushComments(start)
+		p.separate(start)
+		element(i)
+		if i+1 < n {
+			p.write(",")
+			// 次の要素が同じ行で始まるなら行末のコメントはそちらのもの
+			if next, _ := span(i + 1); next == last {
+				p.lastLine = last
+				continue
+			}
+		}
+		p.trailingComments(last)
+	}
+	p.lastLine = 0
+	p.flushComments(end.Line)
+	p.indent--
+	p.newline()
+	p.write(close)
+	p.lastLine = end.Line
+}
+
+func precedence(exp ast.Expression) int {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return precedences[exp.Operator]
+	case *ast.PrefixExpression, *ast.SpreadExpression:
+		return prefix
+	case *ast.IntegerLiteral:
+		// 最適化で作られた負の数は前置演算子と同じ扱い
+		if exp.Value < 0 {
+			return prefix
+		}
+	case *ast.FloatLiteral:
+		if exp.Value < 0 {
+			return prefix
+		}
+	}
+	return atom
+}
+
+// quote writes s as a string literal using the escapes the lexer knows.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// lines returns the first and last source lines of node.
+func lines(node ast.Node) (first, last int) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		for _, tok := range nodeTokens(n) {
+			if tok.Line == 0 {
+				continue
+			}
+			if first == 0 || tok.Line < first {
+				first = tok.Line
+			}
+			if tok.Line > last {
+				last = tok.Line
+			}
+		}
+		return true
+	})
+	return first, last
+}
+
+// nodeTokens returns the tokens stored in node: its Token field and, for
+// blocks and switch statements, the closing brace.
+func nodeTokens(node ast.Node) []token.Token {
+	v := reflect.Indirect(reflect.ValueOf(node))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	tokens := []token.Token{}
+	for _, name := range []string{"Token", "End"} {
+		if f := v.FieldByName(name); f.IsValid() {
+			if tok, ok := f.Interface().(token.Token); ok {
+				tokens = append(tokens, tok)
+			}
+		}
+	}
+	return tokens
+}
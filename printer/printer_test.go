@@ -0,0 +1,243 @@
+package printer
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=5", "let x = 5;\n"},
+		{"let   add = fn(a,b){a+b};", "let add = fn(a, b) { a + b };\n"},
+		{"(1 + 2) * 3 - (4 - 5) - 6", "(1 + 2) * 3 - (4 - 5) - 6;\n"},
+		{"-(a + b) * !c", "-(a + b) * !c;\n"},
+		{"a + b * c == d && e", "a + b * c == d && e;\n"},
+		{"(a[0] + f(1)(2)).name", "(a[0] + f(1)(2)).name;\n"},
+		{`{"b": 1, "a": [1,2], 3: true}`, `{"b": 1, "a": [1, 2], 3: true};` + "\n"},
+		{`"tab\there \"quoted\" \\ done\n"`, `"tab\there \"quoted\" \\ done\n";` + "\n"},
+		{"1.50 + 2", "1.50 + 2;\n"},
+		{"f(a, ...rest)", "f(a, ...rest);\n"},
+		{"let f = fn(a, b = 2 * 3, ...rest) { a }", "let f = fn(a, b = 2 * 3, ...rest) { a };\n"},
+		{"let n:int=1", "let n: int = 1;\n"},
+		{"let f = fn(a:int, b:{string:[float]} = {}, ...r:[any]):fn(int):bool { a }",
+			"let f = fn(a: int, b: {string: [float]} = {}, ...r: [any]): fn(int): bool { a };\n"},
+		{"fn fact(n) {\nif (n < 2) { return 1; }\nn * fact(n - 1)\n}",
+			"fn fact(n) {\n\tif (n < 2) {\n\t\treturn 1;\n\t}\n\tn * fact(n - 1);\n}\n"},
+		{"if (x) { 1 } else { 2 }", "if (x) { 1 } else { 2 }\n"},
+		{"if (x) {\n1 } else { 2 }", "if (x) {\n\t1;\n} else {\n\t2;\n}\n"},
+		{"if (x) { 1 } else {\n2 }", "if (x) {\n\t1;\n} else {\n\t2;\n}\n"},
+		{"if (x) { 1 } else { let y = 2; y }", "if (x) {\n\t1;\n} else {\n\tlet y = 2;\n\ty;\n}\n"},
+		{"for (let i = 0; i < 10; ++i) {\nputs(i)\n}", "for (let i = 0; i < 10; ++i) {\n\tputs(i);\n}\n"},
+		{"let f = fn() {}", "let f = fn() {};\n"},
+		{"switch x {\ncase x > 1:\nputs(x)\nbreak;\ncase true:\nbreak;\n}",
+			"switch x {\ncase x > 1:\n\tputs(x);\n\tbreak;\ncase true:\n\tbreak;\n}\n"},
+		{"switch {\ncase true:\nbreak;\n}", "switch {\ncase true:\n\tbreak;\n}\n"},
+		{`import "a"; import "b.monkey" as b; import {x, y as z} from "c"; export let v = 1;`,
+			"import \"a\";\nimport \"b.monkey\" as b;\nimport { x, y as z } from \"c\";\nexport let v = 1;\n"},
+		{"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;", "let a = 1;\n\nlet b = 2;\nlet c = 3;\n"},
+		{"#!/usr/bin/env monkey\nputs(1)", "#!/usr/bin/env monkey\nputs(1);\n"},
+		{"++i", "++i;\n"},
+		{"fn f() { 1 }; (f)()", "fn f() { 1 }\nf();\n"},
+		{"fn f() { 1 }; -1", "fn f() { 1 };\n-1;\n"},
+		{"if (x) { 1 }; [2]", "if (x) { 1 };\n[2];\n"},
+	}
+
+	for _, tt := range tests {
+		out, err := Format([]byte(tt.input))
+		if err != nil {
+			t.Errorf("%q: %s", tt.input, err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("%q: wrong output.\ngot:\n%s\nwant:\n%s", tt.input, out, tt.expected)
+		}
+	}
+}
+
+func TestFormatComments(t *testing.T) {
+	input := `// header
+
+let x = 1; // one
+let f = fn(a) {
+  // leading
+  let b = a;
+
+  // after blank
+  b // result
+  // end of body
+};
+// between
+switch x {
+  // before case
+  case true:
+    puts(x) // trailing
+    break;
+  // dangling
+}
+// footer`
+
+	expected := `// header
+
+let x = 1; // one
+let f = fn(a) {
+	// leading
+	let b = a;
+
+	// after blank
+	b; // result
+	// end of body
+};
+// between
+switch x {
+// before case
+case true:
+	puts(x); // trailing
+	break;
+// dangling
+}
+// footer
+`
+
+	out, err := Format([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("wrong output.\ngot:\n%s\nwant:\n%s", out, expected)
+	}
+}
+
+func TestFormatLiteralComments(t *testing.T) {
+	input := `let a = [1, // one
+  // before two
+  2, [3, // three
+  4]
+  // end of a
+];
+let h = {
+  "x": 1, // x
+
+  "y": fn() {
+    2 // in y
+  } // y
+};`
+
+	expected := `let a = [
+	1, // one
+	// before two
+	2,
+	[
+		3, // three
+		4
+	]
+	// end of a
+];
+let h = {
+	"x": 1, // x
+
+	"y": fn() {
+		2; // in y
+	} // y
+};
+`
+
+	out, err := Format([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("wrong output.\ngot:\n%s\nwant:\n%s", out, expected)
+	}
+}
+
+func TestFormatIsIdempotent(t *testing.T) {
+	inputs := []string{
+		"let x = fn(a) { if (a) { a } else { fn(b) { b } } }; // c\n",
+		"if (x) { 1 }\n-1",
+		"let h = {\"a\": fn() {\nlet y = 1;\ny\n}};",
+		"let a = [1,\n// inside\n2];",
+		"let h = {\"a\": 1, // a\n\"b\": [2 // b\n]};",
+		"let b = [ // head\n1]; // after\nputs({\"k\": [1, // x\n2]})",
+	}
+
+	files, err := filepath.Glob("../testdata/modules/*.monkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(src))
+	}
+
+	for _, input := range inputs {
+		once, err := Format([]byte(input))
+		if err != nil {
+			t.Errorf("%q: %s", input, err)
+			continue
+		}
+		twice, err := Format(once)
+		if err != nil {
+			t.Errorf("%q: formatted code does not parse: %s\n%s", input, err, once)
+			continue
+		}
+		if string(once) != string(twice) {
+			t.Errorf("%q: formatting is not idempotent.\nfirst:\n%s\nsecond:\n%s", input, once, twice)
+		}
+		if strings.Count(input, "//") != strings.Count(string(once), "//") {
+			t.Errorf("%q: comments lost.\n%s", input, once)
+		}
+	}
+}
+
+// TestFormatRoundTrip checks that formatted code parses to the same
+// program as the input.
+func TestFormatRoundTrip(t *testing.T) {
+	inputs := []string{
+		"for (let i = 0; i < n; puts(i)) {}",
+		"for (j; j < 2; ++j) {}",
+		"for (let i = 0; i < 3; let i = i + 1) { puts(i) }",
+		"for (f(); g(); h()) {\nputs(1)\n}",
+		"let h = {\"a\": [1, 2], \"b\": fn(x) { x * 2 }}; h[\"b\"](h[\"a\"][0])",
+		"switch x {\ncase x > 1:\nputs(x)\nbreak;\ncase true:\n1\n}",
+		"fn f(a, b = 1, ...c) { if (a) { return b; } else { c } }",
+	}
+
+	for _, input := range inputs {
+		want := parse(t, input)
+		out, err := Format([]byte(input))
+		if err != nil {
+			t.Errorf("%q: %s", input, err)
+			continue
+		}
+		if got := parse(t, string(out)); got != want {
+			t.Errorf("%q: formatted code parses to a different program.\nformatted:\n%s\ngot:  %s\nwant: %s", input, out, got, want)
+		}
+	}
+}
+
+// parse returns the String() of the program in src.
+func parse(t *testing.T, src string) string {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Errorf("%q does not parse: %s", src, p.Errors()[0])
+	}
+	return program.String()
+}
+
+func TestFormatParseError(t *testing.T) {
+	if _, err := Format([]byte("let = 5;")); err == nil {
+		t.Errorf("expected an error for invalid input")
+	}
+}
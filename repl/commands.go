@@ -0,0 +1,205 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+// command is a REPL command such as `:env`, written at the start of an
+// input line.
+type command struct {
+	name string
+	args string // :help に表示する引数の説明
+	help string
+	run  func(s *session, arg string)
+}
+
+var commands []command
+
+// help は commands を参照するので init で登録する
+func init() {
+	commands = []command{
+		{"env", "", "list the bindings of the session", (*session).env},
+		{"type", "<expr>", "evaluate expr and show the type of its value", (*session).typeOf},
+		{"ast", "<expr>", "show the parse tree of expr", (*session).ast},
+		{"load", "<file>", "evaluate file in the session", (*session).load},
+		{"reset", "", "remove all bindings", (*session).reset},
+		{"save", "<file>", "write the inputs evaluated so far to file as a script", (*session).save},
+		{"snapshot", "<file>", "write the bindings of the session to file", (*session).snapshot},
+		{"restore", "<file>", "bind the variables saved by :snapshot", (*session).restore},
+		{"time", "<expr>", "evaluate expr and show how long it took", (*session).time},
+		{"help", "", "show this help", (*session).help},
+	}
+}
+
+// command runs a line starting with ':'.
+func (s *session) command(line string) {
+	name, arg := line[1:], ""
+	if i := strings.IndexAny(name, " \t\n"); i >= 0 {
+		name, arg = name[:i], strings.TrimSpace(name[i:])
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			c.run(s, arg)
+			return
+		}
+	}
+	s.errorf("unknown command :%s (see :help)", name)
+}
+
+func (s *session) errorf(format string, a ...interface{}) {
+	io.WriteString(s.out, "\t"+fmt.Sprintf(format, a...)+"\n")
+}
+
+func (s *session) env(arg string) {
+	for _, name := range s.global.Names() {
+		val, _ := s.global.Get(name)
+		fmt.Fprintf(s.out, "%s: %s = %s\n", name, val.Type(), summary(val))
+	}
+}
+
+// summary is the first line of the inspected value, so that functions are
+// shown by their signature.
+func summary(obj object.Object) string {
+	text := obj.Inspect()
+	if i := strings.Index(text, "\n"); i >= 0 {
+		return strings.TrimSuffix(text[:i], " {") + " {...}"
+	}
+	return text
+}
+
+func (s *session) typeOf(arg string) {
+	if arg == "" {
+		s.errorf("usage: :type <expr>")
+		return
+	}
+	// 調べるための式なので :save には残さない
+	if val, ok := s.run(arg, false); ok {
+		if err, isErr := val.(*object.Error); isErr {
+			io.WriteString(s.out, inspect(err)+"\n")
+			return
+		}
+		io.WriteString(s.out, string(val.Type())+"\n")
+	}
+}
+
+func (s *session) ast(arg string) {
+	if arg == "" {
+		s.errorf("usage: :ast <expr>")
+		return
+	}
+	p := parser.New(lexer.New(arg))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParseErrors(s.out, p.Errors())
+		return
+	}
+	ast.Fprint(s.out, program)
+}
+
+func (s *session) load(arg string) {
+	if arg == "" {
+		s.errorf("usage: :load <file>")
+		return
+	}
+	src, err := ioutil.ReadFile(arg)
+	if err != nil {
+		s.errorf("%s", err)
+		return
+	}
+
+	// import をファイルからの相対パスで解決する
+	prev := s.global.File()
+	s.global.SetFile(arg)
+	defer s.global.SetFile(prev)
+	s.eval(string(src))
+}
+
+func (s *session) reset(arg string) {
+	s.global = object.NewEnvironment()
+	s.inputs = nil
+	io.WriteString(s.out, "environment reset\n")
+}
+
+func (s *session) save(arg string) {
+	if arg == "" {
+		s.errorf("usage: :save <file>")
+		return
+	}
+	script := ""
+	for _, input := range s.inputs {
+		script += input + "\n"
+	}
+	if err := ioutil.WriteFile(arg, []byte(script), 0644); err != nil {
+		s.errorf("%s", err)
+		return
+	}
+	fmt.Fprintf(s.out, "saved %d inputs to %s\n", len(s.inputs), arg)
+}
+
+func (s *session) snapshot(arg string) {
+	if arg == "" {
+		s.errorf("usage: :snapshot <file>")
+		return
+	}
+	data, skipped := evaluator.Snapshot(s.global)
+	for _, msg := range skipped {
+		s.errorf("skipped %s", msg)
+	}
+	if err := ioutil.WriteFile(arg, data, 0644); err != nil {
+		s.errorf("%s", err)
+		return
+	}
+	fmt.Fprintf(s.out, "saved %d bindings to %s\n", len(s.global.Names())-len(skipped), arg)
+}
+
+func (s *session) restore(arg string) {
+	if arg == "" {
+		s.errorf("usage: :restore <file>")
+		return
+	}
+	data, err := ioutil.ReadFile(arg)
+	if err != nil {
+		s.errorf("%s", err)
+		return
+	}
+	if err := evaluator.Restore(data, s.global); err != nil {
+		s.errorf("%s", err)
+		return
+	}
+	fmt.Fprintf(s.out, "restored %s\n", arg)
+}
+
+func (s *session) time(arg string) {
+	if arg == "" {
+		s.errorf("usage: :time <expr>")
+		return
+	}
+	start := time.Now()
+	val, ok := s.run(arg, false)
+	elapsed := time.Since(start)
+	if ok {
+		s.print(val, arg)
+		fmt.Fprintf(s.out, "time: %s\n", elapsed)
+	}
+}
+
+func (s *session) help(arg string) {
+	for _, c := range commands {
+		usage := ":" + c.name
+		if c.args != "" {
+			usage += " " + c.args
+		}
+		fmt.Fprintf(s.out, "%-16s %s\n", usage, c.help)
+	}
+}
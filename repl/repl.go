@@ -4,69 +4,379 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/Bo0km4n/dummy-monkey/ast"
 	"github.com/Bo0km4n/dummy-monkey/object"
 
 	"github.com/Bo0km4n/dummy-monkey/evaluator"
 
 	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/lineedit"
+	"github.com/Bo0km4n/dummy-monkey/optimizer"
 	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/resolver"
+	"github.com/Bo0km4n/dummy-monkey/token"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT = ">> "
+	// 括弧や文字列が閉じていない間に表示するプロンプト
+	CONTINUATION_PROMPT = ".. "
+)
+
+// Optimize makes Execute run the optimizer over the program before
+// evaluating it.
+var Optimize bool
+
+// session is the state of a REPL: the environment its inputs are
+// evaluated in and where results are written.
+type session struct {
+	global *object.Environment
+	out    io.Writer
+	inputs []string // 評価に成功した入力. :save で書き出す
+}
+
+// HistoryFile is where the lines entered at a terminal are kept. If it is
+// empty, they are kept in .monkey_history in the user's home directory.
+var HistoryFile string
 
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	s := &session{global: object.NewEnvironment(), out: out}
+	r := s.lineReader(in, out)
+	evaluator.Stdout = out
 
 	for {
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		line, ok := readInput(r)
+		if !ok {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParseErrors(out, p.Errors())
+		if line := strings.TrimSpace(line); strings.HasPrefix(line, ":") {
+			s.command(line)
 			continue
 		}
+		s.eval(line)
+	}
+}
+
+// lineReader reads the lines of the REPL's input, showing prompt first.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// scanReader reads lines from input that is not a terminal.
+type scanReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
 
-		evalueated := evaluator.Eval(program, env)
-		if evalueated != nil {
-			io.WriteString(out, evalueated.Inspect())
-			io.WriteString(out, "\n")
-		} else {
-			io.WriteString(out, fmt.Sprintf("could'nt evaluate expression: %s\n", program.String()))
+func (r *scanReader) ReadLine(prompt string) (string, error) {
+	io.WriteString(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
 		}
+		return "", io.EOF
 	}
+	return r.scanner.Text(), nil
 }
 
-func FileExecute(file *os.File, out io.Writer) {
-	d, _ := ioutil.ReadAll(file)
+// editorReader reads lines from a terminal with the line editor and
+// records them in its history.
+type editorReader struct {
+	*lineedit.Editor
+}
+
+func (r editorReader) ReadLine(prompt string) (string, error) {
+	line, err := r.Editor.ReadLine(prompt)
+	if err == nil {
+		r.History.Add(line)
+	}
+	return line, err
+}
+
+// lineReader returns the line editor if in is a terminal, and a plain
+// line scanner otherwise.
+func (s *session) lineReader(in io.Reader, out io.Writer) lineReader {
+	f, ok := in.(*os.File)
+	if !ok || !lineedit.IsTerminal(int(f.Fd())) {
+		return &scanReader{scanner: bufio.NewScanner(in), out: out}
+	}
 
-	io.WriteString(out, string(d)+"\n"+"(↑ input code)====================================(↓ output)\n")
+	e := lineedit.New(in, out)
+	if file := historyFile(); file != "" {
+		// 履歴が読めなくても編集はできる
+		if h, err := lineedit.LoadHistory(file); err == nil {
+			e.History = h
+		}
+	}
+	e.Complete = s.complete
+	return editorReader{e}
+}
+
+func historyFile() string {
+	if HistoryFile != "" {
+		return HistoryFile
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".monkey_history")
+}
+
+// complete returns the words that can be completed at the cursor:
+// commands at the start of the line, and otherwise keywords, builtins and
+// the variables bound in the session.
+func (s *session) complete(word string) []string {
+	if strings.HasPrefix(word, ":") {
+		names := []string{}
+		for _, c := range commands {
+			names = append(names, ":"+c.name)
+		}
+		return names
+	}
+	names := append(token.Keywords(), evaluator.BuiltinNames()...)
+	return append(names, s.global.Names()...)
+}
+
+// run parses, resolves and evaluates src in the session. It prints parse
+// and resolve errors and reports whether src was evaluated. With record
+// set, src is kept for :save if it evaluates without an error.
+func (s *session) run(src string, record bool) (object.Object, bool) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParseErrors(s.out, p.Errors())
+		return nil, false
+	}
+	if !resolve(s.out, program, s.global, true) {
+		return nil, false
+	}
+	val := evaluator.Eval(program, s.global)
+	if _, isErr := val.(*object.Error); !isErr && record {
+		s.inputs = append(s.inputs, terminate(src, program))
+	}
+	return val, true
+}
+
+// terminate adds a semicolon to src if it ends with a statement that
+// takes one, so that the inputs can be joined into a script without the
+// next line continuing the last expression.
+func terminate(src string, program *ast.Program) string {
+	src = strings.TrimSpace(src)
+	n := len(program.Statements)
+	if n == 0 || strings.HasSuffix(src, ";") {
+		return src
+	}
+	switch program.Statements[n-1].(type) {
+	case *ast.ExpressionStatement, *ast.LetStatement, *ast.ReturnStatement:
+		return src + ";"
+	}
+	return src
+}
+
+// eval evaluates src and prints the result.
+func (s *session) eval(src string) {
+	if val, ok := s.run(src, true); ok {
+		s.print(val, src)
+	}
+}
+
+func (s *session) print(val object.Object, src string) {
+	if val != nil {
+		io.WriteString(s.out, inspect(val))
+		io.WriteString(s.out, "\n")
+	} else {
+		io.WriteString(s.out, fmt.Sprintf("could'nt evaluate expression: %s\n", src))
+	}
+}
+
+// readInput reads lines from r until they form a complete input:
+// brackets and strings are closed and the parser does not run out of
+// tokens. Ctrl-C discards the lines read so far. It returns false when
+// the input ends before a line is read.
+func readInput(r lineReader) (string, bool) {
+next:
+	for {
+		src, err := r.ReadLine(PROMPT)
+		if err == lineedit.ErrInterrupted {
+			continue
+		}
+		if err != nil {
+			return "", false
+		}
+
+		for incomplete(src) {
+			line, err := r.ReadLine(CONTINUATION_PROMPT)
+			if err == lineedit.ErrInterrupted {
+				continue next
+			}
+			// 入力が終わったら読めたところまでを評価する
+			if err != nil {
+				break
+			}
+			src += "\n" + line
+		}
+		return src, true
+	}
+}
+
+// incomplete reports whether src needs more lines: it has an unclosed
+// bracket or string, or the parser reached the end of input in the
+// middle of a statement.
+func incomplete(src string) bool {
+	depth := 0
+	inString := false
+	for i := 0; i < len(src); i++ {
+		ch := src[i]
+		switch {
+		case inString:
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+		case ch == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		}
+	}
+	if inString || depth > 0 {
+		return true
+	}
+	if depth < 0 {
+		// 閉じ括弧が多すぎる. 続きを読んでも直らないのでエラーにする
+		return false
+	}
+
+	p := parser.New(lexer.New(src))
+	p.ParseProgram()
+	for _, msg := range p.Errors() {
+		if strings.Contains(msg, "got EOF") || strings.Contains(msg, "for EOF") {
+			return true
+		}
+	}
+	return false
+}
+
+// ArgsVariable is the global variable holding the arguments of a script
+// run by Execute.
+const ArgsVariable = "args"
+
+// Echo makes Execute print the source of the program before its output.
+var Echo bool
+
+// Verbose makes Execute and the REPL print the warnings of the resolver,
+// such as declarations that shadow a variable.
+var Verbose bool
+
+// Execute runs the program src read from file, binding args to the
+// ArgsVariable array. Output and the final value are written to out and
+// errors to errOut. It returns the exit status: 0 on success, 1 on a
+// runtime error and 2 on a parse error.
+func Execute(src []byte, file string, args []string, out, errOut io.Writer) int {
+	// 出力はまとめて書き出す. exit() は終了前に Flush する
+	buffered := bufio.NewWriter(out)
+	defer buffered.Flush()
+	out = buffered
+
+	if Echo {
+		io.WriteString(out, string(src)+"\n"+"(↑ input code)====================================(↓ output)\n")
+	}
 	env := object.NewEnvironment()
-	l := lexer.New(string(d))
-	p := parser.New(l)
+	env.SetFile(file)
+	elements := make([]object.Object, len(args))
+	for i, arg := range args {
+		elements[i] = &object.String{Value: arg}
+	}
+	env.Set(ArgsVariable, &object.Array{Elements: elements})
+	evaluator.Args = args
+	evaluator.Stdout = out
+
+	p := parser.New(lexer.New(string(src)))
 	program := p.ParseProgram()
 	if len(p.Errors()) != 0 {
-		printParseErrors(out, p.Errors())
-		return
+		printParseErrors(errOut, p.Errors())
+		return 2
+	}
+	if !resolve(errOut, program, env, false) {
+		return 2
+	}
+	// 最適化で消える文や分岐も実行されなかったと報告されるよう先に登録する
+	if evaluator.Coverage != nil {
+		name := file
+		if name == "" {
+			name = "-"
+		}
+		evaluator.Coverage.Add(name, src, program)
+	}
+	if Optimize {
+		optimizer.Optimize(program)
 	}
 
-	evalueated := evaluator.Eval(program, env)
-	if evalueated != nil {
-		io.WriteString(out, evalueated.Inspect())
+	evaluated := evaluator.EvalScript(program, env)
+	if err, ok := evaluated.(*object.Error); ok {
+		buffered.Flush()
+		io.WriteString(errOut, inspect(err)+"\n")
+		return 1
+	}
+	if evaluated != nil {
+		io.WriteString(out, inspect(evaluated))
 		io.WriteString(out, "\n")
-	} else {
-		io.WriteString(out, fmt.Sprintf("could'nt evaluate expression: %s\n", program.String()))
 	}
+	return 0
+}
+
+// DumpOptimized writes the program src as it looks after optimization.
+// It returns 2 if src cannot be parsed.
+func DumpOptimized(src []byte, out, errOut io.Writer) int {
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParseErrors(errOut, p.Errors())
+		return 2
+	}
+
+	for _, stmt := range optimizer.Optimize(program).Statements {
+		io.WriteString(out, stmt.String()+"\n")
+	}
+	return 0
+}
+
+// inspect renders an evaluation result, with a traceback for errors.
+func inspect(obj object.Object) string {
+	if err, ok := obj.(*object.Error); ok {
+		return err.Traceback()
+	}
+	return obj.Inspect()
+}
+
+// resolve runs the resolver over program, treating the variables already
+// bound in env as declared, and late binding variables used in functions
+// if lateBind is set. It prints the warnings with Verbose set and reports
+// whether the program is free of errors.
+func resolve(out io.Writer, program *ast.Program, env *object.Environment, lateBind bool) bool {
+	r := resolver.New(append(evaluator.BuiltinNames(), env.Names()...)...)
+	r.LateBind = lateBind
+	r.Resolve(program)
+	if Verbose {
+		for _, msg := range r.Warnings() {
+			io.WriteString(out, "\twarning: "+msg+"\n")
+		}
+	}
+	printParseErrors(out, r.Errors())
+	return len(r.Errors()) == 0
 }
 
 func printParseErrors(out io.Writer, errors []string) {
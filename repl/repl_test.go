@@ -0,0 +1,256 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/lineedit"
+	"github.com/Bo0km4n/dummy-monkey/object"
+)
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 1;", false},
+		{"let f = fn(n) {", true},
+		{"let f = fn(n) {\n n\n}", false},
+		{"puts(1,", true},
+		{"[1, 2", true},
+		{`"abc`, true},
+		{`"a{b"`, false},
+		{`"a\"{"`, false},
+		{"1 // (", false},
+		{"let x =", true},
+		{"if (x) { 1 } else", true},
+		{"let x = 5 +", true},
+		{"1 + )", false},
+		{"let = 5;", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := incomplete(tt.input); got != tt.expected {
+			t.Errorf("incomplete(%q) = %t, want %t", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestStartMultiLine(t *testing.T) {
+	src, err := ioutil.ReadFile("../testdata/fizzbuzz.monkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	Start(strings.NewReader(string(src)), &out)
+
+	lines := strings.Split(out.String(), "\n")
+	expected := []string{"Fizz", "Buzz", "FizzBuzz"}
+	for _, want := range expected {
+		found := false
+		for _, line := range lines {
+			if strings.HasSuffix(line, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "expected") || strings.Contains(out.String(), "no prefix parse function") {
+		t.Errorf("parse errors in output:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), CONTINUATION_PROMPT) {
+		t.Errorf("no continuation prompt in output:\n%s", out.String())
+	}
+}
+
+func TestStartEvaluatesIncompleteInputAtEOF(t *testing.T) {
+	var out bytes.Buffer
+	Start(strings.NewReader("let f = fn(n) {\nn"), &out)
+	if !strings.Contains(out.String(), "fn f(n)") {
+		t.Errorf("unterminated input was not evaluated at EOF:\n%s", out.String())
+	}
+}
+
+// run drives Start with input and returns the output without prompts.
+func run(input string) string {
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+	text := strings.Replace(out.String(), CONTINUATION_PROMPT, "", -1)
+	return strings.Replace(text, PROMPT, "", -1)
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":env", ""},
+		{"let x = 1;\nlet s = \"a\";\nlet f = fn(a, b) {\na + b\n};\n:env",
+			"1\na\nfn f(a, b) {\n(a + b)\n}\n" +
+				"f: FUNCTION = fn f(a, b) {...}\ns: STRING = a\nx: INTEGER = 1\n"},
+		{":type 1 + 2", "INTEGER\n"},
+		{":type [1]", "ARRAY\n"},
+		{`:type 1 + "a"`, "ERROR: type mismatch: INTEGER + STRING\n"},
+		{":ast 1 + x", "Program\n  Statements[0]: ExpressionStatement 1:1\n    Expression: InfixExpression 1:3\n" +
+			"      Left: IntegerLiteral 1:1\n        Value: 1\n      Operator: \"+\"\n      Right: Identifier 1:5\n        Value: \"x\"\n"},
+		{":ast let = 1", "\texpected next token to be IDENT, got = instead\n\tno prefix parse function for = found\n"},
+		{"let x = 1;\n:reset\nx", "1\nenvironment reset\n\tundefined variable: x (line 1, column 1)\n"},
+		{":nope", "\tunknown command :nope (see :help)\n"},
+		{":type", "\tusage: :type <expr>\n"},
+		{":load no/such/file.monkey", "\topen no/such/file.monkey: no such file or directory\n"},
+	}
+
+	for _, tt := range tests {
+		if got := run(tt.input); got != tt.expected {
+			t.Errorf("%q: wrong output.\ngot:\n%s\nwant:\n%s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestLateBoundGlobals(t *testing.T) {
+	input := `let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } };
+let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } };
+:type even(10)
+let x = 1; let f = fn(x) { x };`
+
+	// odd は後の入力で定義される. 変数の隠蔽は警告しない
+	got := run(input)
+	if strings.Contains(got, "\t") || !strings.Contains(got, "BOOLEAN\n") {
+		t.Errorf("wrong output:\n%s", got)
+	}
+
+	Verbose = true
+	defer func() { Verbose = false }()
+	got = run("let x = 1; let f = fn(x) { x };")
+	if !strings.Contains(got, "\twarning: declaration of x shadows variable") {
+		t.Errorf("no shadowing warning with Verbose set:\n%s", got)
+	}
+}
+
+func TestLoadCommand(t *testing.T) {
+	out := run(":load ../testdata/modules/main.monkey\n:env")
+	if strings.Contains(out, "\t") {
+		t.Fatalf("errors loading file:\n%s", out)
+	}
+	if !strings.Contains(out, ": ") {
+		t.Errorf("no bindings after :load:\n%s", out)
+	}
+}
+
+func TestTimeCommand(t *testing.T) {
+	out := run(":time 1 + 2")
+	if !strings.HasPrefix(out, "3\ntime: ") {
+		t.Errorf("wrong output: %q", out)
+	}
+}
+
+func TestHelpCommand(t *testing.T) {
+	out := run(":help")
+	for _, c := range commands {
+		if !strings.Contains(out, ":"+c.name) {
+			t.Errorf("help does not mention :%s:\n%s", c.name, out)
+		}
+	}
+}
+
+// keys is a lineReader returning the given lines, with nil standing for
+// Ctrl-C.
+type keys []*string
+
+func (k *keys) ReadLine(prompt string) (string, error) {
+	if len(*k) == 0 {
+		return "", io.EOF
+	}
+	line := (*k)[0]
+	*k = (*k)[1:]
+	if line == nil {
+		return "", lineedit.ErrInterrupted
+	}
+	return *line, nil
+}
+
+func TestReadInputInterrupted(t *testing.T) {
+	line := func(s string) *string { return &s }
+	r := &keys{line("let f = fn(n) {"), nil, line("1 +"), line("2")}
+
+	src, ok := readInput(r)
+	if !ok || src != "1 +\n2" {
+		t.Errorf("wrong input after Ctrl-C. got=%q, %t", src, ok)
+	}
+	if _, ok := readInput(r); ok {
+		t.Errorf("expected end of input")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := &session{global: object.NewEnvironment(), out: ioutil.Discard}
+	s.eval("let counter = 1;")
+
+	words := s.complete("c")
+	for _, want := range []string{"counter", "case", "len", "fn"} {
+		if !contains(words, want) {
+			t.Errorf("%q not in completions %v", want, words)
+		}
+	}
+
+	words = s.complete(":")
+	if !contains(words, ":help") || contains(words, "let") {
+		t.Errorf("wrong command completions %v", words)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, el := range list {
+		if el == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSaveCommand(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.monkey")
+	out := run("let x = 1\nputs(\"hi\")\nlet y = x + z;\nlet f = fn(a) {\na + x\n}\nf(1) + \"a\"\n:type puts(\"type\")\n:time puts(\"time\")\nswitch { case true: x }\n:save " + file)
+	if !strings.HasSuffix(out, "saved 4 inputs to "+file+"\n") {
+		t.Errorf("wrong output: %q", out)
+	}
+
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// :type と :time の式は保存されない
+	expected := "let x = 1;\nputs(\"hi\");\nlet f = fn(a) {\na + x\n};\nswitch { case true: x }\n"
+	if string(src) != expected {
+		t.Errorf("wrong script.\ngot:\n%s\nwant:\n%s", src, expected)
+	}
+
+	if out := run(":load " + file + "\n:env"); !strings.Contains(out, "hi\n") || !strings.Contains(out, "x: INTEGER = 1\n") {
+		t.Errorf("saved script does not replay:\n%s", out)
+	}
+}
+
+func TestSnapshotAndRestoreCommands(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.json")
+	out := run("let x = 2;\nlet h = {\"k\": [x]};\nlet sq = fn(n) { n * n };\nlet p = puts;\n:snapshot " + file)
+	if !strings.HasSuffix(out, "\tskipped p: cannot save BUILTIN\nsaved 3 bindings to "+file+"\n") {
+		t.Errorf("wrong output: %q", out)
+	}
+
+	out = run(":restore " + file + "\nsq(h[\"k\"][0])")
+	if out != "restored "+file+"\n4\n" {
+		t.Errorf("wrong output after restore: %q", out)
+	}
+
+	if out := run(":restore no/such/file.json"); out != "\topen no/such/file.json: no such file or directory\n" {
+		t.Errorf("wrong output: %q", out)
+	}
+}
@@ -0,0 +1,354 @@
+// Package resolver binds variable references in a program to the
+// environment slots that hold them, and reports undefined variables,
+// duplicate parameters and shadowed variables before the program runs.
+//
+// Function bodies and for loops get an environment of their own at run
+// time. Variables declared in them are assigned a slot in that environment
+// and every reference records how many environments out (Depth) and at
+// which index (Slot) the variable lives. Top-level variables, builtins and
+// bindings of a REPL session stay unresolved and are looked up by name.
+package resolver
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+)
+
+type scope struct {
+	outer    *scope
+	global   bool
+	function bool
+
+	// スコープ内で宣言される全ての変数 (名前 => 添字)
+	hoisted map[string]int
+	// 現在位置までに宣言された変数
+	declared map[string]bool
+	// 宣言位置 (警告メッセージ用)
+	positions map[string]*ast.Identifier
+}
+
+func newScope(outer *scope, function bool) *scope {
+	return &scope{
+		outer:     outer,
+		function:  function,
+		hoisted:   map[string]int{},
+		declared:  map[string]bool{},
+		positions: map[string]*ast.Identifier{},
+	}
+}
+
+type Resolver struct {
+	// LateBind leaves references inside functions to top-level variables
+	// that are not declared yet unresolved instead of reporting them, so
+	// that a REPL session can declare them in a later input.
+	LateBind bool
+
+	predeclared map[string]bool
+	scope       *scope
+	errors      []string
+	warnings    []string
+}
+
+// New returns a resolver for programs that run in an environment that
+// already binds predeclared, such as builtin names or the variables of a
+// REPL session.
+func New(predeclared ...string) *Resolver {
+	r := &Resolver{predeclared: map[string]bool{}}
+	for _, name := range predeclared {
+		r.predeclared[name] = true
+	}
+	return r
+}
+
+func (r *Resolver) Errors() []string {
+	return r.errors
+}
+
+func (r *Resolver) Warnings() []string {
+	return r.warnings
+}
+
+func (r *Resolver) errorf(ident *ast.Identifier, format string, a ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, a...)+position(ident))
+}
+
+func (r *Resolver) warnf(ident *ast.Identifier, format string, a ...interface{}) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, a...)+position(ident))
+}
+
+func position(ident *ast.Identifier) string {
+	return fmt.Sprintf(" (line %d, column %d)", ident.Token.Line, ident.Token.Column)
+}
+
+// Resolve resolves every identifier in program.
+func (r *Resolver) Resolve(program *ast.Program) {
+	r.scope = newScope(nil, false)
+	r.scope.global = true
+	r.hoist(program.Statements)
+
+	for _, stmt := range program.Statements {
+		r.resolve(stmt)
+	}
+}
+
+// hoist collects the variables declared by stmts in the current scope,
+// without entering nested functions and for loops, so that closures can
+// refer to variables declared after them.
+func (r *Resolver) hoist(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		r.hoistNode(stmt)
+	}
+}
+
+func (r *Resolver) hoistNode(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.LetStatement:
+		r.hoistName(node.Name.Value)
+		r.hoistNode(node.Value)
+	case *ast.ExportStatement:
+		r.hoistNode(node.Statement)
+	case *ast.ImportStatement:
+		for _, name := range importBindings(node) {
+			r.hoistName(name)
+		}
+	case *ast.ExpressionStatement:
+		r.hoistNode(node.Expression)
+	case *ast.FunctionLiteral:
+		if node.Name != nil {
+			r.hoistName(node.Name.Value)
+		}
+	case *ast.IfExpression:
+		r.hoist(node.Consequence.Statements)
+		if node.Alternative != nil {
+			r.hoist(node.Alternative.Statements)
+		}
+	case *ast.SwitchStatement:
+		for _, c := range node.Case {
+			r.hoist(c.Statements)
+		}
+	}
+}
+
+func (r *Resolver) hoistName(name string) {
+	if _, ok := r.scope.hoisted[name]; !ok {
+		r.scope.hoisted[name] = len(r.scope.hoisted)
+	}
+}
+
+// declare binds ident in the current scope.
+func (r *Resolver) declare(ident *ast.Identifier) {
+	s := r.scope
+	if !s.declared[ident.Value] {
+		if outer := r.lookupDeclaration(s.outer, ident.Value); outer != nil {
+			r.warnf(ident, "declaration of %s shadows variable declared at line %d, column %d",
+				ident.Value, outer.Token.Line, outer.Token.Column)
+		}
+	}
+	r.hoistName(ident.Value)
+	s.declared[ident.Value] = true
+	if _, ok := s.positions[ident.Value]; !ok {
+		s.positions[ident.Value] = ident
+	}
+
+	if !s.global {
+		ident.Resolved = true
+		ident.Depth = 0
+		ident.Slot = s.hoisted[ident.Value]
+	}
+}
+
+func (r *Resolver) lookupDeclaration(s *scope, name string) *ast.Identifier {
+	for ; s != nil; s = s.outer {
+		if ident, ok := s.positions[name]; ok {
+			return ident
+		}
+	}
+	return nil
+}
+
+// use resolves a reference to a variable.
+func (r *Resolver) use(ident *ast.Identifier) {
+	depth := 0
+	nested := false // 参照が内側の関数の中にあるか
+
+	for s := r.scope; s != nil; s = s.outer {
+		_, hoisted := s.hoisted[ident.Value]
+		visible := s.declared[ident.Value] || nested && hoisted
+
+		if s.global {
+			// 関数の中の参照は呼ばれるまで名前で引かれない
+			if visible || r.predeclared[ident.Value] || nested && r.LateBind {
+				ident.Resolved = false
+				return
+			}
+			break
+		}
+		if visible {
+			ident.Resolved = true
+			ident.Depth = depth
+			ident.Slot = s.hoisted[ident.Value]
+			return
+		}
+
+		if s.function {
+			nested = true
+		}
+		depth++
+	}
+
+	r.errorf(ident, "undefined variable: %s", ident.Value)
+}
+
+func (r *Resolver) resolveBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	for _, stmt := range block.Statements {
+		r.resolve(stmt)
+	}
+}
+
+func (r *Resolver) resolve(node ast.Node) {
+	switch node := node.(type) {
+	case nil:
+	case *ast.LetStatement:
+		r.resolve(node.Value)
+		r.declare(node.Name)
+	case *ast.ExportStatement:
+		if !r.scope.global {
+			r.errorf(node.Statement.Name, "export is only allowed at the top level")
+		}
+		r.resolve(node.Statement)
+	case *ast.ImportStatement:
+		if !r.scope.global {
+			r.errorf(&ast.Identifier{Token: node.Token}, "import is only allowed at the top level")
+		}
+		for _, name := range importBindings(node) {
+			r.scope.declared[name] = true
+		}
+	case *ast.ReturnStatement:
+		r.resolve(node.ReturnValue)
+	case *ast.ExpressionStatement:
+		r.resolve(node.Expression)
+	case *ast.BlockStatement:
+		r.resolveBlock(node)
+	case *ast.DoublePlusStatement:
+		r.use(node.Name)
+	case *ast.SwitchStatement:
+		r.resolve(node.Expression)
+		for _, c := range node.Case {
+			r.resolve(c.Condition)
+			for _, stmt := range c.Statements {
+				r.resolve(stmt)
+			}
+		}
+	case *ast.Identifier:
+		r.use(node)
+	case *ast.PrefixExpression:
+		r.resolve(node.Right)
+	case *ast.InfixExpression:
+		r.resolve(node.Left)
+		r.resolve(node.Right)
+	case *ast.IfExpression:
+		r.resolve(node.Condition)
+		r.resolveBlock(node.Consequence)
+		r.resolveBlock(node.Alternative)
+	case *ast.ForExpression:
+		r.resolveFor(node)
+	case *ast.FunctionLiteral:
+		r.resolveFunction(node)
+	case *ast.CallExpression:
+		r.resolve(node.Function)
+		for _, arg := range node.Arguments {
+			r.resolve(arg)
+		}
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			r.resolve(el)
+		}
+	case *ast.IndexExpression:
+		r.resolve(node.Left)
+		r.resolve(node.Index)
+	case *ast.HashLiteral:
+		for key, value := range node.Pairs {
+			r.resolve(key)
+			r.resolve(value)
+		}
+	case *ast.DotExpression:
+		r.resolve(node.Left)
+	case *ast.SpreadExpression:
+		r.resolve(node.Value)
+	}
+}
+
+func (r *Resolver) resolveFunction(fn *ast.FunctionLiteral) {
+	// fn name() {...} は外側のスコープで宣言する
+	if fn.Name != nil {
+		r.declare(fn.Name)
+	}
+
+	r.scope = newScope(r.scope, true)
+	defer func() { r.scope = r.scope.outer }()
+
+	// 引数は先頭から順に添字を割り当てる
+	seen := map[string]bool{}
+	params := append([]*ast.Identifier{}, fn.Parameters...)
+	if fn.Rest != nil {
+		params = append(params, fn.Rest)
+	}
+	for _, p := range params {
+		if seen[p.Value] {
+			r.errorf(p, "duplicate parameter %s", p.Value)
+		}
+		seen[p.Value] = true
+		r.hoistName(p.Value)
+	}
+	r.hoist(fn.Body.Statements)
+
+	for i, p := range fn.Parameters {
+		if i < len(fn.Defaults) {
+			r.resolve(fn.Defaults[i])
+		}
+		r.declare(p)
+	}
+	if fn.Rest != nil {
+		r.declare(fn.Rest)
+	}
+	r.resolveBlock(fn.Body)
+
+	fn.Slots = len(r.scope.hoisted)
+}
+
+func (r *Resolver) resolveFor(node *ast.ForExpression) {
+	r.scope = newScope(r.scope, false)
+	defer func() { r.scope = r.scope.outer }()
+
+	r.hoistNode(node.InitStatement)
+	r.hoist(node.Consequence.Statements)
+
+	r.resolve(node.InitStatement)
+	r.resolve(node.FinishCondition)
+	r.resolveBlock(node.Consequence)
+	r.resolve(node.LoopStatement)
+
+	node.Slots = len(r.scope.hoisted)
+}
+
+// importBindings returns the names an import statement binds.
+func importBindings(node *ast.ImportStatement) []string {
+	if node.Names != nil {
+		names := []string{}
+		for _, n := range node.Names {
+			names = append(names, n.Binding().Value)
+		}
+		return names
+	}
+	if node.Alias != nil {
+		return []string{node.Alias.Value}
+	}
+	base := filepath.Base(node.Path.Value)
+	return []string{strings.TrimSuffix(base, filepath.Ext(base))}
+}
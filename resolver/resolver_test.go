@@ -0,0 +1,199 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors: %v", len(p.Errors()), p.Errors())
+	}
+	return program
+}
+
+// identifiers collects the identifiers of program named name, in order.
+func identifiers(node ast.Node, name string) []*ast.Identifier {
+	found := []*ast.Identifier{}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Identifier); ok && ident.Value == name {
+			found = append(found, ident)
+		}
+		return true
+	})
+	return found
+}
+
+func TestResolveSlots(t *testing.T) {
+	input := `
+	let g = 1;
+	let f = fn(a, b) {
+		let c = a + b;
+		let inner = fn() { c + a + g };
+		inner()
+	};
+	`
+	program := parse(t, input)
+	r := New()
+	r.Resolve(program)
+	if len(r.Errors()) != 0 {
+		t.Fatalf("resolver has errors: %v", r.Errors())
+	}
+
+	tests := []struct {
+		name     string
+		index    int
+		resolved bool
+		depth    int
+		slot     int
+	}{
+		{"g", 0, false, 0, 0},
+		{"g", 1, false, 0, 0},
+		{"a", 0, true, 0, 0},
+		{"b", 0, true, 0, 1},
+		{"a", 1, true, 0, 0},
+		{"c", 0, true, 0, 2},
+		{"inner", 0, true, 0, 3},
+		{"c", 1, true, 1, 2},
+		{"a", 2, true, 1, 0},
+		{"inner", 1, true, 0, 3},
+	}
+
+	for _, tt := range tests {
+		idents := identifiers(program, tt.name)
+		if tt.index >= len(idents) {
+			t.Fatalf("identifier %s #%d not found", tt.name, tt.index)
+		}
+		ident := idents[tt.index]
+		if ident.Resolved != tt.resolved || ident.Depth != tt.depth || ident.Slot != tt.slot {
+			t.Errorf("%s #%d: got resolved=%t depth=%d slot=%d, want resolved=%t depth=%d slot=%d",
+				tt.name, tt.index, ident.Resolved, ident.Depth, ident.Slot, tt.resolved, tt.depth, tt.slot)
+		}
+	}
+
+	fn := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if fn.Slots != 4 {
+		t.Errorf("fn.Slots wrong. got=%d, want=4", fn.Slots)
+	}
+}
+
+func TestResolveForLoop(t *testing.T) {
+	input := `let f = fn(n) { for (let i = 0; i < n; ++i) { let x = i; } };`
+	program := parse(t, input)
+	r := New()
+	r.Resolve(program)
+	if len(r.Errors()) != 0 {
+		t.Fatalf("resolver has errors: %v", r.Errors())
+	}
+
+	n := identifiers(program, "n")
+	if n[1].Depth != 1 || n[1].Slot != 0 {
+		t.Errorf("n in loop condition: got depth=%d slot=%d, want depth=1 slot=0", n[1].Depth, n[1].Slot)
+	}
+	x := identifiers(program, "x")
+	if x[0].Depth != 0 || x[0].Slot != 1 {
+		t.Errorf("x in loop body: got depth=%d slot=%d, want depth=0 slot=1", x[0].Depth, x[0].Slot)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = y;", []string{"undefined variable: y (line 1, column 9)"}},
+		{"let f = fn() { x }; let x = 1;", []string{}},
+		{"let f = fn() { let a = b; let b = 1; };", []string{"undefined variable: b (line 1, column 24)"}},
+		{"let f = fn() { let g = fn() { b }; let b = 1; };", []string{}},
+		{"let f = fn(a, b, a) { a };", []string{"duplicate parameter a (line 1, column 18)"}},
+		{"let f = fn(a, ...a) { a };", []string{"duplicate parameter a (line 1, column 18)"}},
+		{"fn fact(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(5);", []string{}},
+		{"let f = fn() { import \"strings\" };", []string{"import is only allowed at the top level (line 1, column 16)"}},
+		{"len(puts)", []string{}},
+		{"unknown(1)", []string{"undefined variable: unknown (line 1, column 1)"}},
+		{"import \"strings\"; strings.upper(\"a\")", []string{}},
+		{"import \"lib/mathx\" as m; m.square(2)", []string{}},
+		{"import { square as sq } from \"lib/mathx\"; sq(2)", []string{}},
+	}
+
+	for _, tt := range tests {
+		r := New("len", "puts")
+		r.Resolve(parse(t, tt.input))
+		errors := r.Errors()
+		if len(errors) != len(tt.expected) {
+			t.Errorf("%q: wrong number of errors. got=%v, want=%v", tt.input, errors, tt.expected)
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errors[i] != msg {
+				t.Errorf("%q: wrong error. got=%q, want=%q", tt.input, errors[i], msg)
+			}
+		}
+	}
+}
+
+func TestResolveLateBind(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } };", []string{}},
+		{"let f = fn() { fn() { g() } };", []string{}},
+		{"odd(1)", []string{"undefined variable: odd (line 1, column 1)"}},
+		{"let x = y;", []string{"undefined variable: y (line 1, column 9)"}},
+	}
+
+	for _, tt := range tests {
+		r := New()
+		r.LateBind = true
+		program := parse(t, tt.input)
+		r.Resolve(program)
+		errors := r.Errors()
+		if len(errors) != len(tt.expected) {
+			t.Errorf("%q: wrong number of errors. got=%v, want=%v", tt.input, errors, tt.expected)
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errors[i] != msg {
+				t.Errorf("%q: wrong error. got=%q, want=%q", tt.input, errors[i], msg)
+			}
+		}
+	}
+}
+
+func TestResolveWarnings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; let f = fn(x) { x };",
+			[]string{"declaration of x shadows variable declared at line 1, column 5 (line 1, column 23)"}},
+		{"let x = 1; let f = fn() { let x = 2; x };",
+			[]string{"declaration of x shadows variable declared at line 1, column 5 (line 1, column 31)"}},
+		{"let x = 1; let x = 2;", []string{}},
+		{"let f = fn(len) { len };", []string{}},
+	}
+
+	for _, tt := range tests {
+		r := New("len")
+		r.Resolve(parse(t, tt.input))
+		if len(r.Errors()) != 0 {
+			t.Errorf("%q: resolver has errors: %v", tt.input, r.Errors())
+		}
+		warnings := r.Warnings()
+		if len(warnings) != len(tt.expected) {
+			t.Errorf("%q: wrong number of warnings. got=%v, want=%v", tt.input, warnings, tt.expected)
+			continue
+		}
+		for i, msg := range tt.expected {
+			if warnings[i] != msg {
+				t.Errorf("%q: wrong warning. got=%q, want=%q", tt.input, warnings[i], msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Bo0km4n/dummy-monkey/ast"
+	"github.com/Bo0km4n/dummy-monkey/evaluator"
+	"github.com/Bo0km4n/dummy-monkey/lexer"
+	"github.com/Bo0km4n/dummy-monkey/object"
+	"github.com/Bo0km4n/dummy-monkey/parser"
+	"github.com/Bo0km4n/dummy-monkey/resolver"
+)
+
+const (
+	// testSuffix is the ending of the names of test scripts.
+	testSuffix = "_test.monkey"
+	// testPrefix starts the names of the test functions in them.
+	testPrefix = "test_"
+)
+
+// testCommand implements `monkey test [-run regexp] [-v] [-coverage file] [path...]`.
+// It runs the test scripts found in paths, the current directory by
+// default: each script is evaluated and then every top-level function
+// whose name starts with test_ is called. A test fails when it returns an
+// error, as the assert builtins do. It returns 1 when a test or script
+// fails and 2 when the paths cannot be read. With -coverage the coverage
+// summary follows the results.
+func testCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	run := flags.String("run", "", "run only the tests whose names match the regular expression")
+	verbose := flags.Bool("v", false, "list every test as it runs")
+	coverFile := flags.String("coverage", "", coverageUsage)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	filter, err := regexp.Compile(*run)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid -run: %s\n", err)
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := monkeyFiles(paths)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	var stop func() error
+	if *coverFile != "" {
+		stop = startCoverage(*coverFile, stdout, stderr)
+	}
+	t := &tester{out: stdout, filter: filter, verbose: *verbose}
+	found := false
+	for _, file := range files {
+		if strings.HasSuffix(file, testSuffix) {
+			found = true
+			t.runFile(file)
+		}
+	}
+
+	status := 0
+	switch {
+	case !found:
+		fmt.Fprintln(stderr, "no test files")
+	case t.failed > 0 || t.brokenFiles > 0:
+		fmt.Fprintf(stdout, "FAIL: %d passed, %d failed\n", t.passed, t.failed)
+		status = 1
+	default:
+		fmt.Fprintf(stdout, "ok: %d passed\n", t.passed)
+	}
+	if stop != nil {
+		if err := stop(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+	return status
+}
+
+type tester struct {
+	out     io.Writer
+	filter  *regexp.Regexp
+	verbose bool
+
+	passed, failed int
+	brokenFiles    int // 読み込めなかったファイル
+}
+
+func (t *tester) runFile(file string) {
+	passed, failed := t.passed, t.failed
+	if !t.runTests(file) {
+		t.brokenFiles++
+		fmt.Fprintf(t.out, "FAIL\t%s\n", file)
+		return
+	}
+	if t.failed > failed {
+		fmt.Fprintf(t.out, "FAIL\t%s\t%d passed, %d failed\n", file, t.passed-passed, t.failed-failed)
+		return
+	}
+	fmt.Fprintf(t.out, "ok\t%s\t%d passed\n", file, t.passed-passed)
+}
+
+// runTests evaluates file and runs its tests. It reports false if the
+// file cannot be read, parsed or evaluated.
+func (t *tester) runTests(file string) bool {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(t.out, "\t%s\n", err)
+		return false
+	}
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.printErrors(p.Errors())
+		return false
+	}
+	r := resolver.New(evaluator.BuiltinNames()...)
+	r.Resolve(program)
+	if len(r.Errors()) != 0 {
+		t.printErrors(r.Errors())
+		return false
+	}
+	if evaluator.Coverage != nil {
+		evaluator.Coverage.Add(file, src, program)
+	}
+
+	env := object.NewEnvironment()
+	env.SetFile(file)
+	evaluator.Stdout = t.out
+	evaluator.Args = nil
+	if err, ok := evaluator.EvalScript(program, env).(*object.Error); ok {
+		t.printErrors([]string{err.Traceback()})
+		return false
+	}
+
+	for _, name := range testNames(program) {
+		if !t.filter.MatchString(name) {
+			continue
+		}
+		fn, _ := env.Get(name)
+		if t.verbose {
+			fmt.Fprintf(t.out, "=== RUN   %s\n", name)
+		}
+
+		start := time.Now()
+		result := evaluator.Apply(fn)
+		elapsed := time.Since(start).Seconds()
+
+		if err, ok := result.(*object.Error); ok {
+			t.failed++
+			fmt.Fprintf(t.out, "--- FAIL: %s (%.2fs)\n", name, elapsed)
+			t.printErrors([]string{err.Traceback()})
+			continue
+		}
+		t.passed++
+		if t.verbose {
+			fmt.Fprintf(t.out, "--- PASS: %s (%.2fs)\n", name, elapsed)
+		}
+	}
+	return true
+}
+
+func (t *tester) printErrors(errors []string) {
+	for _, msg := range errors {
+		fmt.Fprintf(t.out, "\t%s\n", strings.Replace(msg, "\n", "\n\t", -1))
+	}
+}
+
+// testNames returns the names of the test functions declared at the top
+// level of program, with let or fn, in source order.
+func testNames(program *ast.Program) []string {
+	names := []string{}
+	for _, stmt := range program.Statements {
+		var name string
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			if _, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+				name = stmt.Name.Value
+			}
+		case *ast.ExpressionStatement:
+			if fn, ok := stmt.Expression.(*ast.FunctionLiteral); ok && fn.Name != nil {
+				name = fn.Name.Value
+			}
+		}
+		if strings.HasPrefix(name, testPrefix) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
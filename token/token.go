@@ -1,10 +1,14 @@
 package token
 
+import "sort"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int // 1始まりの行番号
+	Column  int // 1始まりの列番号 (バイト単位)
 }
 
 var keywords = map[string]TokenType{
@@ -19,6 +23,18 @@ var keywords = map[string]TokenType{
 	"switch": SWITCH,
 	"break":  BREAK,
 	"case":   CASE,
+	"import": IMPORT,
+	"export": EXPORT,
+}
+
+// Keywords returns the reserved words of the language, sorted.
+func Keywords() []string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func LookupIdent(ident string) TokenType {
@@ -31,11 +47,13 @@ func LookupIdent(ident string) TokenType {
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // 構文解析器には渡されない
 
 	// 識別子 + リテラル
 	IDENT  = "IDENT"
 	INT    = "INT"
 	HEX    = "HEX"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
 
 	// 演算子
@@ -61,6 +79,8 @@ const (
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
+	ELLIPSIS  = "..."
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -81,4 +101,6 @@ const (
 	SWITCH   = "SWITCH"
 	BREAK    = "BREAK"
 	CASE     = "CASE"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
 )